@@ -4,18 +4,24 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/Moon1706/ginkgo2allure/internal/app"
-	"github.com/Moon1706/ginkgo2allure/pkg/convert/parser"
+	"github.com/Moon1706/ginkgo2allure/pkg/config"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert"
+	fmngr "github.com/Moon1706/ginkgo2allure/pkg/convert/file_manager"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/input"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/metadata"
 	"github.com/Moon1706/ginkgo2allure/pkg/convert/report"
-	"github.com/Moon1706/ginkgo2allure/pkg/convert/transform"
+	"github.com/Moon1706/ginkgo2allure/pkg/upload"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
 const (
-	CountArgs              = 2
+	MinCountArgs           = 2
 	CountAnalyzeErrorsArgs = 2
 
 	FlagEpic            = "epic"
@@ -23,7 +29,33 @@ const (
 	FlagMandatoryLabels = "mandatory_labels"
 	FlagAnalyzeErrors   = "analyze_errors"
 	FlagAutoGenID       = "auto_gen_id"
+	FlagEntryPrefix     = "attachment_entry_prefix"
+	FlagCategories      = "categories"
+	FlagEnv             = "env"
+	FlagInputFormat     = "input_format"
+	FlagSuitesStrategy  = "suites_strategy"
+	FlagSeverityLabels  = "severity_labels"
+	FlagLinkTemplate    = "link_template"
+	FlagOnDuplicate     = "on_duplicate"
+	FlagWorkers         = "workers"
+	FlagManifest        = "manifest"
+	FlagClean           = "clean"
+	FlagRequireEmpty    = "require_empty"
+	FlagOutputArchive   = "output_archive"
+	FlagUploadURL       = "upload_url"
+	FlagUploadProject   = "upload_project"
+	FlagUploadToken     = "upload_token"
+	FlagUploadRetries   = "upload_retries"
+	FlagJUnitOut        = "junit_out"
+	FlagFailOnFailures  = "fail_on_test_failures"
+	FlagFailOnLabels    = "fail_on_missing_labels"
+	FlagLabelFilter     = "label_filter"
+	FlagSpecStates      = "spec_states"
+	FlagConfig          = "config"
+	FlagProfile         = "profile"
 	FlagLogLevel        = "log_level"
+
+	EnvUploadToken = "GINKGO2ALLURE_UPLOAD_TOKEN"
 )
 
 var (
@@ -31,40 +63,174 @@ var (
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "ginkgo2allure ./ginkgo-report.json ./save/allure/reports/folder/path/",
+	Use:   "ginkgo2allure ./ginkgo-report.json [./more/ginkgo-reports/*.json ...] ./save/allure/reports/folder/path/",
 	Short: "Convert Ginkgo report to Allure report",
 	Long: `Prototype of a tool that converts Ginkgo JSON reports to Allure JSON reports
-in a separate folder allure-results.`,
-	Args: cobra.MatchAll(cobra.ExactArgs(CountArgs), cobra.OnlyValidArgs),
+in a separate folder allure-results. Several Ginkgo reports (or glob patterns)
+can be merged into one folder, the last argument is always the folder.`,
+	Args:              cobra.MatchAll(cobra.MinimumNArgs(MinCountArgs-1), cobra.OnlyValidArgs),
+	PersistentPreRunE: applyConfig,
+	// The count of arguments is checked after the config is applied, as the config can save
+	// results to an archive or upload them.
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		outputArchive, _ := cmd.Flags().GetString(FlagOutputArchive)
+		uploadURL, _ := cmd.Flags().GetString(FlagUploadURL)
+		if outputArchive != "" && uploadURL != "" {
+			return fmt.Errorf("--%s and --%s can't be used together", FlagOutputArchive, FlagUploadURL)
+		}
+		for _, flag := range []string{FlagManifest, FlagClean, FlagRequireEmpty} {
+			if value, _ := cmd.Flags().GetBool(flag); value && outputArchive != "" {
+				return fmt.Errorf("--%s and --%s can't be used together", FlagOutputArchive, flag)
+			}
+		}
+		if !hasOutputFolder(cmd) {
+			return nil
+		}
+		return cobra.MinimumNArgs(MinCountArgs)(cmd, args)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
-		config := parser.Config{}
 		logger, err := buildLogger(logLevel)
 		if err != nil {
 			panic(err)
 		}
+		appConfig := getAppConfig(cmd, logger)
+		if !hasOutputFolder(cmd) {
+			app.StartConvertion(cmd.Context(), args, "", appConfig, logger)
+			return
+		}
+		app.StartConvertion(cmd.Context(), args[:len(args)-1], args[len(args)-1], appConfig, logger)
+	},
+}
 
-		epic, err := cmd.Flags().GetString(FlagEpic)
-		if err == nil && epic != "" {
-			config.LabelsScraperOpts = append(config.LabelsScraperOpts, report.WithEpic(epic))
+// applyConfig sets flags which aren't set in the command line from the config file, its profile
+// and environment variables.
+func applyConfig(cmd *cobra.Command, _ []string) error {
+	path, _ := cmd.Flags().GetString(FlagConfig)
+	profile, _ := cmd.Flags().GetString(FlagProfile)
+	settings, err := config.Load(path, profile, os.Getenv)
+	if err != nil {
+		return err
+	}
+	values := settings.Values()
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		value, ok := values[flag.Name]
+		if !ok || flag.Changed || err != nil {
+			return
 		}
-		labelSpliter, err := cmd.Flags().GetString(FlagLabelSeparator)
-		if err == nil && labelSpliter != "" {
-			config.LabelsScraperOpts = append(config.LabelsScraperOpts, report.WithLabelSpliter(labelSpliter))
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			err = slice.Replace(value)
+		} else {
+			err = flag.Value.Set(value[0])
 		}
-		mandatoryLabels, err := cmd.Flags().GetStringSlice(FlagMandatoryLabels)
-		if err == nil && len(mandatoryLabels) != 0 {
-			config.ReportOpts = append(config.ReportOpts, report.WithMandatoryLabels(mandatoryLabels))
+		if err != nil {
+			err = fmt.Errorf("incorrect setting %s: %w", flag.Name, err)
 		}
-		analyzeErrors, err := cmd.Flags().GetBool(FlagAnalyzeErrors)
-		if err == nil {
-			config.TransformOpts = append(config.TransformOpts, transform.WillAnalyzeErrors(analyzeErrors, analyzeErrors))
+	})
+	return err
+}
+
+// getAppConfig builds conversion config from flags, flags which the command doesn't have
+// are skipped.
+func getAppConfig(cmd *cobra.Command, logger *zap.Logger) app.Config {
+	settings := config.Settings{}
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if !settings.IsSetting(flag.Name) {
+			return
 		}
-		autoGenID, err := cmd.Flags().GetBool(FlagAutoGenID)
-		if err == nil {
-			config.LabelsScraperOpts = append(config.LabelsScraperOpts, report.WillAutoGenerateID(autoGenID))
+		value := []string{flag.Value.String()}
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			value = slice.GetSlice()
 		}
-		app.StartConvertion(args[0], args[1], config, logger)
-	},
+		if err := settings.SetValue(flag.Name, value); err != nil {
+			logger.Sugar().Fatal(err)
+		}
+	})
+	parserConfig, err := settings.ParserConfig()
+	if err != nil {
+		logger.Sugar().Fatal(err)
+	}
+	parserConfig.LabelsScraperOpts = append([]report.LabelsScraperOpt{report.WithDebugLog(logger.Sugar().Debugf)},
+		parserConfig.LabelsScraperOpts...)
+	appConfig := app.Config{ParserConfig: parserConfig}
+	appConfig.Metadata, err = settings.MetadataConfig()
+	if err != nil {
+		logger.Sugar().Fatal(err)
+	}
+	if executor, ok := metadata.GetCIExecutor(os.Getenv); ok {
+		appConfig.Metadata.Executor = &executor
+	}
+	inputFormat, err := cmd.Flags().GetString(FlagInputFormat)
+	if err == nil {
+		appConfig.InputFormat = inputFormat
+	}
+	onDuplicate, err := cmd.Flags().GetString(FlagOnDuplicate)
+	if err == nil {
+		appConfig.OnDuplicate = onDuplicate
+	}
+	workers, err := cmd.Flags().GetInt(FlagWorkers)
+	if err == nil {
+		appConfig.Workers = workers
+	}
+	manifest, err := cmd.Flags().GetBool(FlagManifest)
+	if err == nil {
+		appConfig.Manifest = manifest
+	}
+	clean, err := cmd.Flags().GetBool(FlagClean)
+	if err == nil {
+		appConfig.Clean = clean
+	}
+	requireEmpty, err := cmd.Flags().GetBool(FlagRequireEmpty)
+	if err == nil {
+		appConfig.RequireEmpty = requireEmpty
+	}
+	outputArchive, err := cmd.Flags().GetString(FlagOutputArchive)
+	if err == nil {
+		appConfig.OutputArchive = outputArchive
+	}
+	uploadURL, err := cmd.Flags().GetString(FlagUploadURL)
+	if err == nil {
+		appConfig.UploadURL = uploadURL
+	}
+	uploadProject, err := cmd.Flags().GetString(FlagUploadProject)
+	if err == nil {
+		appConfig.UploadOpts = append(appConfig.UploadOpts, upload.WithProjectID(uploadProject))
+	}
+	uploadToken, err := cmd.Flags().GetString(FlagUploadToken)
+	if err == nil {
+		if uploadToken == "" {
+			uploadToken = os.Getenv(EnvUploadToken)
+		}
+		appConfig.UploadOpts = append(appConfig.UploadOpts, upload.WithToken(uploadToken))
+	}
+	uploadRetries, err := cmd.Flags().GetInt(FlagUploadRetries)
+	if err == nil {
+		appConfig.UploadOpts = append(appConfig.UploadOpts, upload.WithRetries(uploadRetries,
+			upload.DefaultRetryDelay))
+	}
+	junitOut, err := cmd.Flags().GetString(FlagJUnitOut)
+	if err == nil {
+		appConfig.JUnitOut = junitOut
+	}
+	failOnFailures, err := cmd.Flags().GetBool(FlagFailOnFailures)
+	if err == nil {
+		appConfig.FailOnTestFailures = failOnFailures
+	}
+	failOnLabels, err := cmd.Flags().GetBool(FlagFailOnLabels)
+	if err == nil {
+		appConfig.FailOnMissingLabels = failOnLabels
+	}
+	return appConfig
+}
+
+// hasOutputFolder checks whether the last argument is the results folder. It isn't when
+// results are saved to an archive or uploaded.
+func hasOutputFolder(cmd *cobra.Command) bool {
+	for _, flag := range []string{FlagOutputArchive, FlagUploadURL} {
+		if value, err := cmd.Flags().GetString(flag); err == nil && value != "" {
+			return false
+		}
+	}
+	return true
 }
 
 func ExecuteContext(ctx context.Context) {
@@ -75,11 +241,55 @@ func ExecuteContext(ctx context.Context) {
 }
 
 func init() {
-	rootCmd.Flags().StringP(FlagEpic, "e", report.DefaultEpic, "epic name")
-	rootCmd.Flags().String(FlagLabelSeparator, report.DefaultLabelSpliter, "labels separator")
-	rootCmd.Flags().StringSlice(FlagMandatoryLabels, []string{report.IDLabelName}, "allure mandatory labels")
-	rootCmd.Flags().Bool(FlagAnalyzeErrors, true, "will analyze test fails in Ginkgo report or not")
-	rootCmd.Flags().Bool(FlagAutoGenID, report.DefaultAutoGenerateID, "will auto generate UUID for Ginkgo test or not")
+	rootCmd.PersistentFlags().StringP(FlagEpic, "e", report.DefaultEpic, "epic name")
+	rootCmd.PersistentFlags().String(FlagLabelSeparator, report.DefaultLabelSpliter, "labels separator")
+	rootCmd.PersistentFlags().String(FlagSuitesStrategy, report.DefaultSuitesStrategy, fmt.Sprintf(
+		"how suite path, suite description and containers map to parentSuite, suite and subSuite labels: %s",
+		strings.Join([]string{report.SuitesStrategyFlat, report.SuitesStrategyPath, report.SuitesStrategyDescription,
+			report.SuitesStrategyContainers}, ", ")))
+	rootCmd.PersistentFlags().StringSlice(FlagSeverityLabels, []string{},
+		"bare labels which are Allure severity instead of tag, e.g. critical,blocker")
+	rootCmd.PersistentFlags().StringArray(FlagLinkTemplate, []string{},
+		"label[:type]=url template of Allure links (type issue, tms or link), e.g. jira:issue=https://jira.local/browse/{}")
+	rootCmd.PersistentFlags().StringSlice(FlagMandatoryLabels, []string{report.IDLabelName}, "allure mandatory labels")
+	rootCmd.PersistentFlags().Bool(FlagAnalyzeErrors, true, "will analyze test fails in Ginkgo report or not")
+	rootCmd.PersistentFlags().Bool(FlagAutoGenID, report.DefaultAutoGenerateID, "will auto generate UUID for Ginkgo test or not")
+	rootCmd.PersistentFlags().String(FlagEntryPrefix, report.DefaultAttachmentEntryPrefix,
+		"report entries with this name prefix will be saved as attachments")
+	rootCmd.PersistentFlags().String(FlagCategories, "", "YAML or JSON file with Allure categories (built-in categories by default)")
+	rootCmd.PersistentFlags().StringArray(FlagEnv, []string{}, "additional KEY=VALUE property of Allure environment")
+	rootCmd.PersistentFlags().String(FlagLabelFilter, "",
+		"convert only specs matching Ginkgo label filter query over suite, container and spec labels, e.g. 'smoke && !flaky'")
+	rootCmd.PersistentFlags().StringSlice(FlagSpecStates, []string{},
+		"convert only specs in these states, e.g. failed,panicked,timedout (all states by default)")
+	rootCmd.PersistentFlags().String(FlagInputFormat, input.FormatJSON, fmt.Sprintf(
+		"format of Ginkgo reports: %s or %s (JUnit XML report)", input.FormatJSON, input.FormatJUnit))
+	rootCmd.Flags().String(FlagOnDuplicate, convert.OnDuplicateLatest, fmt.Sprintf(
+		"what to do with results with the same UUID from several reports: %s (keep the latest) or %s",
+		convert.OnDuplicateLatest, convert.OnDuplicateError))
+	rootCmd.Flags().Int(FlagWorkers, 1, "number of goroutines which convert specs and save results")
+	rootCmd.Flags().Bool(FlagManifest, false, fmt.Sprintf(
+		"save %s with the list of written files", fmngr.DefaultManifestFileName))
+	rootCmd.Flags().Bool(FlagClean, false, "remove everything from the results folder before conversion")
+	rootCmd.Flags().Bool(FlagRequireEmpty, false, "fail if the results folder is not empty")
+	rootCmd.Flags().String(FlagOutputArchive, "",
+		"save results to zip or tar.gz archive instead of the folder, all arguments are Ginkgo reports then")
+	rootCmd.Flags().String(FlagUploadURL, "",
+		"allure-docker-service URL to upload results to instead of the folder, all arguments are Ginkgo reports then")
+	rootCmd.Flags().String(FlagUploadProject, upload.DefaultProjectID, "allure-docker-service project ID")
+	rootCmd.Flags().String(FlagUploadToken, "", fmt.Sprintf(
+		"allure-docker-service access token (%s environment variable by default)", EnvUploadToken))
+	rootCmd.Flags().Int(FlagUploadRetries, upload.DefaultRetries, "number of retries of failed upload requests")
+	rootCmd.Flags().String(FlagJUnitOut, "", "also save JUnit XML report with the same labels and statuses to this path")
+	rootCmd.Flags().Bool(FlagFailOnFailures, false, fmt.Sprintf(
+		"exit with code %d if there are failed or broken results", app.ExitCodeTestFailures))
+	rootCmd.Flags().Bool(FlagFailOnLabels, true, fmt.Sprintf(
+		"exit with code %d if specs are rejected for missing mandatory labels", app.ExitCodeMissingLabels))
+	rootCmd.PersistentFlags().String(FlagConfig, "", fmt.Sprintf(
+		"config file (%s environment variable or %s of the working directory by default)",
+		config.EnvConfig, config.DefaultFileName))
+	rootCmd.PersistentFlags().String(FlagProfile, "", fmt.Sprintf(
+		"profile of the config file (%s environment variable by default)", config.EnvProfile))
 	rootCmd.PersistentFlags().StringVarP(&logLevel, FlagLogLevel, "l", "info", "log level")
 }
 
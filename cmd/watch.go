@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/Moon1706/ginkgo2allure/internal/app"
+	"github.com/spf13/cobra"
+)
+
+const (
+	CountWatchArgs = 2
+
+	FlagInterval = "interval"
+	FlagState    = "state"
+
+	DefaultInterval = 5 * time.Second
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch ./ginkgo/reports/folder/ ./save/allure/reports/folder/path/",
+	Short: "Convert new specs of Ginkgo reports while they are written",
+	Long: `Polls the folder for new or updated Ginkgo JSON reports and converts only specs
+which were not converted before. Converted specs are saved in the state file, so
+restarted watch doesn't duplicate results.`,
+	Args: cobra.ExactArgs(CountWatchArgs),
+	Run: func(cmd *cobra.Command, args []string) {
+		logger, err := buildLogger(logLevel)
+		if err != nil {
+			panic(err)
+		}
+		watchConfig := app.WatchConfig{
+			Config:   getAppConfig(cmd, logger),
+			Interval: DefaultInterval,
+		}
+		interval, err := cmd.Flags().GetDuration(FlagInterval)
+		if err == nil && interval > 0 {
+			watchConfig.Interval = interval
+		}
+		state, err := cmd.Flags().GetString(FlagState)
+		if err == nil {
+			watchConfig.StatePath = state
+		}
+		app.StartWatch(cmd.Context(), args[0], args[1], watchConfig, logger)
+	},
+}
+
+func init() {
+	watchCmd.Flags().Duration(FlagInterval, DefaultInterval, "interval between polls of the reports folder")
+	watchCmd.Flags().String(FlagState, "", "file with converted specs (results folder/"+
+		app.DefaultStateFileName+" by default)")
+	rootCmd.AddCommand(watchCmd)
+}
@@ -1,42 +1,241 @@
 package app
 
 import (
-	"encoding/json"
+	"bufio"
+	"context"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 
 	"github.com/Moon1706/ginkgo2allure/pkg/convert"
 	fmngr "github.com/Moon1706/ginkgo2allure/pkg/convert/file_manager"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/metadata"
 	"github.com/Moon1706/ginkgo2allure/pkg/convert/parser"
-	"github.com/onsi/ginkgo/v2/types"
+	"github.com/Moon1706/ginkgo2allure/pkg/upload"
 	"go.uber.org/zap"
 )
 
-func StartConvertion(ginkgoReportFile, allureReportsFolder string, config parser.Config, logger *zap.Logger) {
+const (
+	// ExitCodeInterrupted is the exit code of conversion stopped by the context (SIGINT or SIGTERM).
+	ExitCodeInterrupted = 130
+	// ExitCodeTestFailures is the exit code of conversion with failed or broken results
+	// when Config.FailOnTestFailures is set.
+	ExitCodeTestFailures = 2
+	// ExitCodeMissingLabels is the exit code of conversion with specs rejected for missing
+	// mandatory labels when Config.FailOnMissingLabels is set.
+	ExitCodeMissingLabels = 3
+)
+
+type Config struct {
+	ParserConfig parser.Config
+	Metadata     metadata.Config
+	// InputFormat is the format of Ginkgo reports: input.FormatJSON or input.FormatJUnit.
+	InputFormat  string
+	OnDuplicate  string
+	Workers      int
+	Manifest     bool
+	Clean        bool
+	RequireEmpty bool
+	// OutputArchive is the path of zip or tar.gz archive, which is saved instead of the results folder.
+	OutputArchive string
+	// UploadURL is allure-docker-service URL, results are uploaded instead of saving to the folder.
+	// It can't be used with OutputArchive.
+	UploadURL  string
+	UploadOpts []upload.Opt
+	// JUnitOut is the path of JUnit XML report, which is saved besides Allure results.
+	JUnitOut            string
+	FailOnTestFailures  bool
+	FailOnMissingLabels bool
+}
+
+type filesGetter interface {
+	GetFiles() []fmngr.MemoryFile
+}
+
+func StartConvertion(ctx context.Context, ginkgoReportPatterns []string, allureReportsFolder string, config Config, logger *zap.Logger) {
 	sugar := logger.Sugar()
 
-	file, err := os.ReadFile(ginkgoReportFile)
+	ginkgoReportFiles, err := ExpandInputs(ginkgoReportPatterns)
 	if err != nil {
-		sugar.Fatal("Error reading file ", ginkgoReportFile)
+		sugar.Fatal("Error expanding input files ", err)
 	}
 
-	var ginkgoReport []types.Report
-	err = json.Unmarshal(file, &ginkgoReport)
+	fileManager, err := newFileManager(ctx, allureReportsFolder, config)
 	if err != nil {
-		sugar.Fatal("Error unmarshaling file ", ginkgoReportFile)
+		sugar.Fatal("Error preparing results folder ", err)
 	}
-
-	allureReports, err := convert.GinkgoToAllureReport(ginkgoReport, parser.NewDefaultParser,
-		config)
-	if err != nil {
-		sugar.Fatal("Error converting report ", ginkgoReportFile, " ", err)
+	// fail removes the temp file of not saved archive before exiting.
+	fail := func(args ...interface{}) {
+		if archive, ok := fileManager.(*fmngr.ArchiveFileManager); ok {
+			_ = archive.Cleanup()
+		}
+		sugar.Fatal(args...)
+	}
+	var junit *fmngr.JUnitFileManager
+	sink := fileManager
+	if config.JUnitOut != "" {
+		junit = fmngr.NewJUnitFileManager()
+		sink = fmngr.NewMultiFileManager(fileManager, junit)
+	}
+	converter := convert.NewStreamConverter(sink, parser.NewDefaultParser, config.ParserConfig,
+		config.OnDuplicate, convert.WithWorkers(config.Workers), convert.WithInputFormat(config.InputFormat),
+		convert.WillRejectSpecsWithoutLabels(true))
+	// Suites are distinguished by inputs only if there are several inputs.
+	if len(ginkgoReportFiles) > 1 {
+		for _, ginkgoReportFile := range ginkgoReportFiles {
+			err = readInput(ginkgoReportFile, func(r io.Reader, inputName string) error {
+				return converter.ScanSuites(ctx, r, inputName)
+			})
+			if ctx.Err() != nil {
+				interrupt(fileManager, sugar)
+			}
+			if err != nil {
+				fail("Error reading file ", ginkgoReportFile, " ", err)
+			}
+		}
+	}
+	for _, ginkgoReportFile := range ginkgoReportFiles {
+		err = readInput(ginkgoReportFile, func(r io.Reader, inputName string) error {
+			return converter.Convert(ctx, r, inputName)
+		})
+		if ctx.Err() != nil {
+			interrupt(fileManager, sugar)
+		}
+		if err != nil {
+			fail("Error converting file ", ginkgoReportFile, " ", err)
+		}
 	}
 
-	fileManager := fmngr.NewFileManager(allureReportsFolder)
-	errs := convert.PrintAllureReports(allureReports, fileManager)
+	errs := converter.GetSaveErrors()
+	errs = append(errs, convert.PrintAllureMetadata(converter.GetReports(), config.Metadata, fileManager)...)
+	if ctx.Err() != nil {
+		// Interrupted while the last files were saved.
+		interrupt(fileManager, sugar)
+	}
 	for _, err := range errs {
 		sugar.Error(err)
 	}
 	if len(errs) != 0 {
-		sugar.Fatal("Saving errors")
+		fail("Saving errors")
+	}
+	if manifestSaver, ok := fileManager.(fmngr.ManifestSaver); ok {
+		err = manifestSaver.SaveManifest()
+		if err != nil {
+			fail(err)
+		}
+	}
+	if archive, ok := fileManager.(*fmngr.ArchiveFileManager); ok {
+		err = archive.Save()
+		if err != nil {
+			fail(err)
+		}
+	}
+	if junit != nil {
+		err = junit.Save(config.JUnitOut)
+		if err != nil {
+			fail(err)
+		}
+	}
+	if files, ok := fileManager.(filesGetter); ok && config.UploadURL != "" {
+		err = upload.NewClient(config.UploadURL, config.UploadOpts...).SendResults(ctx, files.GetFiles())
+		if ctx.Err() != nil {
+			interrupt(fileManager, sugar)
+		}
+		if err != nil {
+			fail("Error uploading results ", err)
+		}
+	}
+	exitCode := checkSummary(converter.GetSummary(), config, sugar)
+	if exitCode != 0 {
+		_ = sugar.Sync()
+		os.Exit(exitCode)
+	}
+}
+
+// checkSummary prints the summary of the conversion and returns the exit code according
+// to the exit code policy of the config.
+func checkSummary(summary *convert.Summary, config Config, sugar *zap.SugaredLogger) int {
+	for _, err := range summary.GetRejected() {
+		sugar.Warn("Spec rejected ", err)
+	}
+	_, err := summary.WriteTo(os.Stdout)
+	if err != nil {
+		sugar.Error(err)
+	}
+	if config.FailOnMissingLabels && len(summary.GetRejected()) != 0 {
+		sugar.Error("Specs rejected for missing labels")
+		return ExitCodeMissingLabels
+	}
+	if config.FailOnTestFailures && summary.HasTestFailures() {
+		sugar.Error("Tests failed")
+		return ExitCodeTestFailures
+	}
+	return 0
+}
+
+func newFileManager(ctx context.Context, allureReportsFolder string, config Config) (fmngr.FileManager, error) {
+	if config.OutputArchive != "" {
+		return fmngr.NewArchiveFileManager(config.OutputArchive)
+	}
+	if config.UploadURL != "" {
+		return fmngr.NewMemoryFileManager(), nil
+	}
+	fmOpts := []fmngr.Opt{
+		fmngr.WithContext(ctx),
+		fmngr.WillCreateFolder(true),
+		fmngr.WillCleanFolder(config.Clean),
+		fmngr.WillRequireEmptyFolder(config.RequireEmpty),
+		fmngr.WillDetectCollisionsOnCreate(true),
+	}
+	if config.Manifest {
+		fmOpts = append(fmOpts, fmngr.WithManifest(fmngr.DefaultManifestFileName))
+	}
+	return fmngr.NewFileManager(allureReportsFolder, fmOpts...)
+}
+
+// interrupt removes files written before the interruption, so the results folder doesn't
+// contain a part of the report, and exits with ExitCodeInterrupted.
+func interrupt(fm fmngr.FileManager, sugar *zap.SugaredLogger) {
+	if cleaner, ok := fm.(fmngr.Cleaner); ok {
+		err := cleaner.Cleanup()
+		if err != nil {
+			sugar.Error("Error removing written files ", err)
+		}
+	}
+	sugar.Error("Conversion interrupted")
+	_ = sugar.Sync()
+	os.Exit(ExitCodeInterrupted)
+}
+
+func readInput(ginkgoReportFile string, read func(io.Reader, string) error) error {
+	file, err := os.Open(ginkgoReportFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return read(bufio.NewReader(file), ginkgoReportFile)
+}
+
+// ExpandInputs expands glob patterns of input files. Each file is returned only once.
+func ExpandInputs(patterns []string) ([]string, error) {
+	files := []string{}
+	seen := map[string]bool{}
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %s", pattern)
+		}
+		for _, match := range matches {
+			if seen[match] {
+				continue
+			}
+			seen[match] = true
+			files = append(files, match)
+		}
 	}
+	return files, nil
 }
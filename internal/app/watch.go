@@ -0,0 +1,144 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Moon1706/ginkgo2allure/pkg/convert"
+	fmngr "github.com/Moon1706/ginkgo2allure/pkg/convert/file_manager"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/input"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/parser"
+	"github.com/onsi/ginkgo/v2/types"
+	"go.uber.org/zap"
+)
+
+// DefaultStateFileName is the file in the results folder with specs converted in watch mode.
+const DefaultStateFileName = ".ginkgo2allure-state.json"
+
+type (
+	WatchConfig struct {
+		Config
+		Interval  time.Duration
+		StatePath string
+	}
+	watchedReport struct {
+		modTime time.Time
+		size    int64
+		// suites are suites of the report without specs for metadata.
+		suites []types.Report
+	}
+)
+
+// StartWatch polls the folder for new or updated Ginkgo reports (*.json or *.xml for JUnit
+// input format) and converts their new specs until the context is done.
+func StartWatch(ctx context.Context, ginkgoReportsFolder string, allureReportsFolder string, config WatchConfig,
+	logger *zap.Logger) {
+	sugar := logger.Sugar()
+
+	statePath := config.StatePath
+	if statePath == "" {
+		statePath = filepath.Join(allureReportsFolder, DefaultStateFileName)
+	}
+	state, err := convert.LoadState(statePath)
+	if err != nil {
+		sugar.Fatal(err)
+	}
+	fileManager, err := fmngr.NewFileManager(allureReportsFolder, fmngr.WithContext(ctx),
+		fmngr.WillCreateFolder(true), fmngr.WillDetectCollisions(true), fmngr.WithSavedFiles(state.Files))
+	if err != nil {
+		sugar.Fatal("Error preparing results folder ", err)
+	}
+	converter := convert.NewIncrementalConverter(fileManager, parser.NewDefaultParser, config.ParserConfig,
+		state.Specs)
+
+	files := map[string]watchedReport{}
+	ticker := time.NewTicker(config.Interval)
+	defer ticker.Stop()
+	for {
+		savedSpecs := len(state.Specs)
+		count := pollReports(ctx, ginkgoReportsFolder, config.InputFormat, files, converter, sugar)
+		if count != 0 {
+			reports := []types.Report{}
+			for _, file := range files {
+				reports = append(reports, file.suites...)
+			}
+			for _, err := range convert.PrintAllureMetadata(reports, config.Metadata, fileManager) {
+				sugar.Error(err)
+			}
+			sugar.Info("Converted new specs: ", count)
+		}
+		fileNames := fileManager.(fmngr.FileLister).GetFileNames()
+		if len(state.Specs) != savedSpecs || len(fileNames) != len(state.Files) {
+			state.Files = fileNames
+			err = state.Save(statePath)
+			if err != nil {
+				sugar.Error(err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			sugar.Info("Watching stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollReports converts new specs of reports changed since the last poll. Reports which
+// can't be read (e.g. are being written) are read again on the next poll.
+func pollReports(ctx context.Context, ginkgoReportsFolder string, inputFormat string,
+	files map[string]watchedReport, converter *convert.IncrementalConverter, sugar *zap.SugaredLogger) int {
+	pattern := "*.json"
+	if inputFormat == input.FormatJUnit {
+		pattern = "*.xml"
+	}
+	paths, err := filepath.Glob(filepath.Join(ginkgoReportsFolder, pattern))
+	if err != nil {
+		sugar.Error(err)
+		return 0
+	}
+	count := 0
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if previous, ok := files[path]; ok && previous.modTime.Equal(info.ModTime()) && previous.size == info.Size() {
+			continue
+		}
+		var ginkgoReports []types.Report
+		file, err := os.ReadFile(path)
+		if err == nil {
+			ginkgoReports, err = input.DecodeReports(bytes.NewReader(file), inputFormat)
+		}
+		if err != nil {
+			sugar.Debug("Skip report ", path, " ", err)
+			continue
+		}
+		rejected := len(converter.GetSummary().GetRejected())
+		converted, errs := converter.Convert(ctx, ginkgoReports, path)
+		count += converted
+		for _, err := range converter.GetSummary().GetRejected()[rejected:] {
+			sugar.Warn("Spec of file ", path, " rejected ", err)
+		}
+		for _, err := range errs {
+			sugar.Error("Error converting file ", path, " ", err)
+		}
+		if len(errs) == 0 {
+			files[path] = watchedReport{modTime: info.ModTime(), size: info.Size(), suites: getSuites(ginkgoReports)}
+		}
+	}
+	return count
+}
+
+func getSuites(ginkgoReports []types.Report) []types.Report {
+	suites := make([]types.Report, 0, len(ginkgoReports))
+	for _, ginkgoReport := range ginkgoReports {
+		ginkgoReport.SpecReports = nil
+		suites = append(suites, ginkgoReport)
+	}
+	return suites
+}
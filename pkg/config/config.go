@@ -0,0 +1,327 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/metadata"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/parser"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/report"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/transform"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DefaultFileName = ".ginkgo2allure.yaml"
+	// EnvPrefix is the prefix of environment variables which override settings, the name is
+	// the upper-cased key, e.g. GINKGO2ALLURE_MANDATORY_LABELS. Lists are comma-separated.
+	EnvPrefix  = "GINKGO2ALLURE_"
+	EnvConfig  = EnvPrefix + "CONFIG"
+	EnvProfile = EnvPrefix + "PROFILE"
+
+	envListSeparator = ","
+)
+
+type (
+	// File is the config file: settings and named profiles which override them.
+	File struct {
+		Settings `yaml:",inline"`
+		Profiles map[string]Settings `yaml:"profiles"`
+	}
+	// Settings are converter options, the keys are the same as CLI flags. Nil fields aren't set
+	// and keep the defaults.
+	Settings struct {
+		Epic                  *string  `yaml:"epic"`
+		LabelSeparator        *string  `yaml:"label_separator"`
+		SuitesStrategy        *string  `yaml:"suites_strategy"`
+		SeverityLabels        []string `yaml:"severity_labels"`
+		MandatoryLabels       []string `yaml:"mandatory_labels"`
+		AutoGenID             *bool    `yaml:"auto_gen_id"`
+		LinkTemplates         []string `yaml:"link_template"`
+		AnalyzeErrors         *bool    `yaml:"analyze_errors"`
+		AttachmentEntryPrefix *string  `yaml:"attachment_entry_prefix"`
+		Categories            *string  `yaml:"categories"`
+		Env                   []string `yaml:"env"`
+
+		LabelFilter *string  `yaml:"label_filter"`
+		SpecStates  []string `yaml:"spec_states"`
+
+		InputFormat         *string        `yaml:"input_format"`
+		OnDuplicate         *string        `yaml:"on_duplicate"`
+		Workers             *int           `yaml:"workers"`
+		Manifest            *bool          `yaml:"manifest"`
+		Clean               *bool          `yaml:"clean"`
+		RequireEmpty        *bool          `yaml:"require_empty"`
+		OutputArchive       *string        `yaml:"output_archive"`
+		UploadURL           *string        `yaml:"upload_url"`
+		UploadProject       *string        `yaml:"upload_project"`
+		UploadToken         *string        `yaml:"upload_token"`
+		UploadRetries       *int           `yaml:"upload_retries"`
+		JUnitOut            *string        `yaml:"junit_out"`
+		FailOnTestFailures  *bool          `yaml:"fail_on_test_failures"`
+		FailOnMissingLabels *bool          `yaml:"fail_on_missing_labels"`
+		LogLevel            *string        `yaml:"log_level"`
+		Interval            *time.Duration `yaml:"interval"`
+		State               *string        `yaml:"state"`
+	}
+)
+
+// Load loads settings of the profile from the config file and overrides them with environment
+// variables. If the path is empty, GINKGO2ALLURE_CONFIG or .ginkgo2allure.yaml of the working
+// directory is used, the missing discovered file means no settings. If the profile is empty,
+// GINKGO2ALLURE_PROFILE is used.
+func Load(path, profile string, getenv func(string) string) (Settings, error) {
+	if path == "" {
+		path = getenv(EnvConfig)
+	}
+	if profile == "" {
+		profile = getenv(EnvProfile)
+	}
+	file := File{}
+	if path == "" {
+		path = DefaultFileName
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			path = ""
+		}
+	}
+	if path != "" {
+		var err error
+		file, err = LoadFile(path)
+		if err != nil {
+			return Settings{}, err
+		}
+	}
+	settings, err := file.GetSettings(profile)
+	if err != nil {
+		return Settings{}, err
+	}
+	err = settings.ApplyEnv(getenv)
+	return settings, err
+}
+
+// LoadParserConfig loads settings like Load with the process environment and returns parser
+// config of them.
+func LoadParserConfig(path, profile string) (parser.Config, error) {
+	settings, err := Load(path, profile, os.Getenv)
+	if err != nil {
+		return parser.Config{}, err
+	}
+	return settings.ParserConfig()
+}
+
+// LoadFile reads the config file, unknown keys are errors.
+func LoadFile(path string) (File, error) {
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return File{}, errors.Wrap(err, "Cannot read config file")
+	}
+	file := File{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	err = decoder.Decode(&file)
+	if err != nil && err != io.EOF {
+		return File{}, errors.Wrap(err, "Failed unmarshal config file")
+	}
+	return file, nil
+}
+
+// GetSettings returns settings overridden by the profile, empty profile means no profile.
+func (f File) GetSettings(profile string) (Settings, error) {
+	settings := f.Settings
+	if profile == "" {
+		return settings, nil
+	}
+	profileSettings, ok := f.Profiles[profile]
+	if !ok {
+		profiles := make([]string, 0, len(f.Profiles))
+		for name := range f.Profiles {
+			profiles = append(profiles, name)
+		}
+		sort.Strings(profiles)
+		return Settings{}, fmt.Errorf("unknown profile %s, config has profiles: %s", profile,
+			strings.Join(profiles, ", "))
+	}
+	settings.Merge(profileSettings)
+	return settings, nil
+}
+
+// Merge overrides settings with the set settings of other.
+func (s *Settings) Merge(other Settings) {
+	fields := s.getFields()
+	for key, field := range other.getFields() {
+		if !field.IsNil() {
+			fields[key].Set(field)
+		}
+	}
+}
+
+// ApplyEnv overrides settings with environment variables, see EnvPrefix.
+func (s *Settings) ApplyEnv(getenv func(string) string) error {
+	for key, field := range s.getFields() {
+		value := getenv(EnvPrefix + strings.ToUpper(key))
+		if value == "" {
+			continue
+		}
+		values := []string{value}
+		if field.Kind() == reflect.Slice {
+			values = strings.Split(value, envListSeparator)
+		}
+		if err := s.SetValue(key, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsSetting checks whether the key is a setting.
+func (s *Settings) IsSetting(key string) bool {
+	_, ok := s.getFields()[key]
+	return ok
+}
+
+// SetValue sets the setting from its string representation, scalar settings take the last value.
+func (s *Settings) SetValue(key string, values []string) error {
+	field, ok := s.getFields()[key]
+	if !ok {
+		return fmt.Errorf("unknown setting %s", key)
+	}
+	if field.Kind() == reflect.Slice {
+		field.Set(reflect.ValueOf(append([]string{}, values...)))
+		return nil
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("setting %s doesn't have value", key)
+	}
+	value := values[len(values)-1]
+	var (
+		parsed interface{}
+		err    error
+	)
+	switch field.Interface().(type) {
+	case *string:
+		parsed = value
+	case *bool:
+		parsed, err = strconv.ParseBool(value)
+	case *int:
+		parsed, err = strconv.Atoi(value)
+	case *time.Duration:
+		parsed, err = time.ParseDuration(value)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "Cannot parse setting %s", key)
+	}
+	ptr := reflect.New(field.Type().Elem())
+	ptr.Elem().Set(reflect.ValueOf(parsed))
+	field.Set(ptr)
+	return nil
+}
+
+// Values returns string representations of set settings by keys.
+func (s Settings) Values() map[string][]string {
+	values := map[string][]string{}
+	for key, field := range s.getFields() {
+		switch {
+		case field.IsNil():
+		case field.Kind() == reflect.Slice:
+			values[key] = append([]string{}, field.Interface().([]string)...)
+		default:
+			values[key] = []string{fmt.Sprint(field.Elem().Interface())}
+		}
+	}
+	return values
+}
+
+// ParserConfig creates parser config with labels, link templates, reports and filters settings.
+func (s Settings) ParserConfig() (parser.Config, error) {
+	config := parser.Config{}
+	if s.Epic != nil && *s.Epic != "" {
+		config.LabelsScraperOpts = append(config.LabelsScraperOpts, report.WithEpic(*s.Epic))
+	}
+	if s.LabelSeparator != nil && *s.LabelSeparator != "" {
+		config.LabelsScraperOpts = append(config.LabelsScraperOpts, report.WithLabelSpliter(*s.LabelSeparator))
+	}
+	if s.SuitesStrategy != nil {
+		if !report.IsSuitesStrategy(*s.SuitesStrategy) {
+			return parser.Config{}, fmt.Errorf("unknown suites strategy %s", *s.SuitesStrategy)
+		}
+		config.LabelsScraperOpts = append(config.LabelsScraperOpts, report.WithSuitesStrategy(*s.SuitesStrategy))
+	}
+	if len(s.SeverityLabels) != 0 {
+		config.LabelsScraperOpts = append(config.LabelsScraperOpts, report.WithSeverityLabels(s.SeverityLabels))
+	}
+	if len(s.LinkTemplates) != 0 {
+		linkTemplates := []report.LinkTemplate{}
+		for _, value := range s.LinkTemplates {
+			linkTemplate, err := report.ParseLinkTemplate(value)
+			if err != nil {
+				return parser.Config{}, err
+			}
+			linkTemplates = append(linkTemplates, linkTemplate)
+		}
+		config.LabelsScraperOpts = append(config.LabelsScraperOpts, report.WithLinkTemplates(linkTemplates))
+	}
+	if s.AutoGenID != nil {
+		config.LabelsScraperOpts = append(config.LabelsScraperOpts, report.WillAutoGenerateID(*s.AutoGenID))
+	}
+	if len(s.MandatoryLabels) != 0 {
+		config.ReportOpts = append(config.ReportOpts, report.WithMandatoryLabels(s.MandatoryLabels))
+	}
+	if s.AttachmentEntryPrefix != nil {
+		config.ReportOpts = append(config.ReportOpts, report.WithAttachmentEntryPrefix(*s.AttachmentEntryPrefix))
+	}
+	if s.AnalyzeErrors != nil {
+		config.TransformOpts = append(config.TransformOpts,
+			transform.WillAnalyzeErrors(*s.AnalyzeErrors, *s.AnalyzeErrors))
+	}
+	if s.LabelFilter != nil || len(s.SpecStates) != 0 {
+		labelFilter := ""
+		if s.LabelFilter != nil {
+			labelFilter = *s.LabelFilter
+		}
+		specFilter, err := parser.NewSpecFilter(labelFilter, s.SpecStates)
+		if err != nil {
+			return parser.Config{}, err
+		}
+		config.SpecFilter = specFilter
+	}
+	return config, nil
+}
+
+// MetadataConfig creates metadata config with categories (built-in by default) and environment
+// properties.
+func (s Settings) MetadataConfig() (metadata.Config, error) {
+	config := metadata.Config{Categories: metadata.GetDefaultCategories()}
+	var err error
+	if s.Categories != nil && *s.Categories != "" {
+		config.Categories, err = metadata.LoadCategories(*s.Categories)
+		if err != nil {
+			return metadata.Config{}, err
+		}
+	}
+	if len(s.Env) != 0 {
+		config.Environment, err = metadata.ParseProperties(s.Env)
+		if err != nil {
+			return metadata.Config{}, err
+		}
+	}
+	return config, nil
+}
+
+func (s *Settings) getFields() map[string]reflect.Value {
+	fields := map[string]reflect.Value{}
+	value := reflect.ValueOf(s).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		key, _, _ := strings.Cut(value.Type().Field(i).Tag.Get("yaml"), ",")
+		fields[key] = value.Field(i)
+	}
+	return fields
+}
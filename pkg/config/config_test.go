@@ -0,0 +1,113 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Moon1706/ginkgo2allure/pkg/config"
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const testConfig = `
+epic: e2e
+mandatory_labels: [id, owner]
+link_template:
+  - jira:issue=https://jira.local/browse/{}
+fail_on_test_failures: false
+profiles:
+  nightly:
+    fail_on_test_failures: true
+    junit_out: nightly.xml
+    interval: 10s
+  pr:
+    label_filter: smoke
+    spec_states: [failed]
+    mandatory_labels: []
+`
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), config.DefaultFileName)
+	assert.Empty(t, os.WriteFile(path, []byte(content), 0o600), "config written")
+	return path
+}
+
+func chdir(t *testing.T, dir string) {
+	wd, err := os.Getwd()
+	assert.Empty(t, err, "working directory")
+	assert.Empty(t, os.Chdir(dir), "working directory changed")
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+}
+
+func getenv(env map[string]string) func(string) string {
+	return func(key string) string { return env[key] }
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfig(t, testConfig)
+	settings, err := config.Load(path, "", getenv(nil))
+	assert.Empty(t, err, "config loaded")
+	assert.Equal(t, map[string][]string{
+		"epic":                  {"e2e"},
+		"mandatory_labels":      {"id", "owner"},
+		"link_template":         {"jira:issue=https://jira.local/browse/{}"},
+		"fail_on_test_failures": {"false"},
+	}, settings.Values(), "settings without profile")
+
+	settings, err = config.Load(path, "nightly", getenv(nil))
+	assert.Empty(t, err, "profile loaded")
+	assert.Equal(t, []string{"true"}, settings.Values()["fail_on_test_failures"], "profile overrides settings")
+	assert.Equal(t, []string{"e2e"}, settings.Values()["epic"], "settings kept")
+	assert.Equal(t, 10*time.Second, *settings.Interval, "duration setting")
+
+	settings, err = config.Load(path, "", getenv(map[string]string{
+		config.EnvProfile:                    "pr",
+		config.EnvPrefix + "EPIC":            "payments",
+		config.EnvPrefix + "SEVERITY_LABELS": "critical,blocker",
+	}))
+	assert.Empty(t, err, "profile from environment loaded")
+	assert.Equal(t, []string{}, settings.MandatoryLabels, "profile clears list")
+	assert.Equal(t, "payments", *settings.Epic, "environment overrides settings")
+	assert.Equal(t, []string{"critical", "blocker"}, settings.SeverityLabels, "comma-separated list")
+
+	_, err = config.Load(path, "release", getenv(nil))
+	assert.ErrorContains(t, err, "config has profiles: nightly, pr", "unknown profile")
+	_, err = config.Load(path, "", getenv(map[string]string{config.EnvPrefix + "WORKERS": "many"}))
+	assert.Error(t, err, "incorrect environment variable")
+	_, err = config.Load(writeConfig(t, "epics: e2e"), "", getenv(nil))
+	assert.Error(t, err, "unknown key")
+	_, err = config.Load(filepath.Join(t.TempDir(), "missing.yaml"), "", getenv(nil))
+	assert.Error(t, err, "missing config file")
+}
+
+func TestLoadDiscover(t *testing.T) {
+	chdir(t, filepath.Dir(writeConfig(t, "workers: 4")))
+	settings, err := config.Load("", "", getenv(nil))
+	assert.Empty(t, err, "config discovered")
+	assert.Equal(t, 4, *settings.Workers, "discovered settings")
+
+	chdir(t, t.TempDir())
+	settings, err = config.Load("", "", getenv(nil))
+	assert.Empty(t, err, "config isn't required")
+	assert.Empty(t, settings.Values(), "no settings")
+}
+
+func TestSettingsParserConfig(t *testing.T) {
+	settings, err := config.Load(writeConfig(t, testConfig), "pr", getenv(nil))
+	assert.Empty(t, err, "config loaded")
+	parserConfig, err := settings.ParserConfig()
+	assert.Empty(t, err, "parser config created")
+	assert.Len(t, parserConfig.LabelsScraperOpts, 2, "epic and link templates")
+	assert.Empty(t, parserConfig.ReportOpts, "mandatory labels cleared by profile")
+	assert.True(t, parserConfig.SpecFilter(nil, types.SpecReport{State: types.SpecStateFailed,
+		LeafNodeLabels: []string{"smoke"}}), "failed smoke spec converted")
+	assert.False(t, parserConfig.SpecFilter(nil, types.SpecReport{State: types.SpecStatePassed,
+		LeafNodeLabels: []string{"smoke"}}), "passed spec filtered")
+
+	assert.Empty(t, settings.SetValue("suites_strategy", []string{"tree"}), "any string")
+	_, err = settings.ParserConfig()
+	assert.Error(t, err, "unknown suites strategy")
+	assert.Error(t, settings.SetValue("unknown", []string{"value"}), "unknown setting")
+}
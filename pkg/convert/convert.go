@@ -1,44 +1,308 @@
 package convert
 
 import (
+	"context"
+	"fmt"
+
 	fmngr "github.com/Moon1706/ginkgo2allure/pkg/convert/file_manager"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/metadata"
 	"github.com/Moon1706/ginkgo2allure/pkg/convert/parser"
 	"github.com/Moon1706/ginkgo2allure/pkg/convert/report"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/transform"
+	"github.com/google/uuid"
 	"github.com/onsi/ginkgo/v2/types"
 	"github.com/ozontech/allure-go/pkg/allure"
 )
 
+var (
+	BeforeSuiteNodeTypes = types.NodeTypeBeforeSuite | types.NodeTypeSynchronizedBeforeSuite |
+		types.NodeTypeReportBeforeSuite
+	AfterSuiteNodeTypes = types.NodeTypeAfterSuite | types.NodeTypeSynchronizedAfterSuite |
+		types.NodeTypeReportAfterSuite | types.NodeTypeCleanupAfterSuite
+)
+
+type (
+	suiteConverter struct {
+		parserCreation parser.CreationFunc
+		config         parser.Config
+		container      allure.Container
+		setupFailure   *types.SpecReport
+		suiteLabels    []string
+		children       map[uuid.UUID]bool
+		// summary counts filtered specs, if it's set.
+		summary *Summary
+	}
+	specOutput struct {
+		result     allure.Result
+		retries    []allure.Result
+		containers []allure.Container
+		err        error
+	}
+)
+
 func GinkgoToAllureReport(ginkgoReports []types.Report, parserCreation parser.CreationFunc,
 	config parser.Config) ([]allure.Result, error) {
+	return GinkgoToAllureReportContext(context.Background(), ginkgoReports, parserCreation, config)
+}
+
+// GinkgoToAllureReportContext stops between specs when the context is done and returns
+// already converted results with the context error.
+func GinkgoToAllureReportContext(ctx context.Context, ginkgoReports []types.Report,
+	parserCreation parser.CreationFunc, config parser.Config) ([]allure.Result, error) {
+	results, _, err := GinkgoToAllureReportWithContainers(ctx, ginkgoReports, parserCreation, config)
+	return results, err
+}
+
+// GinkgoToAllureReportWithContainers converts like GinkgoToAllureReportContext and also returns
+// containers with fixtures of suites and specs.
+func GinkgoToAllureReportWithContainers(ctx context.Context, ginkgoReports []types.Report,
+	parserCreation parser.CreationFunc, config parser.Config) ([]allure.Result, []allure.Container, error) {
 	results := []allure.Result{}
+	containers := []allure.Container{}
 	for _, ginkgoReport := range ginkgoReports {
-		suiteName := ginkgoReport.SuiteDescription
+		suite := newSuiteConverter(ginkgoReport, parserCreation, config)
 		for _, specReport := range ginkgoReport.SpecReports {
-			if specReport.LeafNodeType != types.NodeTypeIt {
-				continue
-			}
-			config.LabelsScraperOpts = append(config.LabelsScraperOpts, report.WithSuiteName(suiteName))
-			p, err := parserCreation(specReport, config)
-			if err != nil {
-				return results, err
+			if err := ctx.Err(); err != nil {
+				return results, containers, err
 			}
-			result, err := p.GetAllureReport()
+			specResults, specContainers, err := suite.convertSpec(specReport)
+			results = append(results, specResults...)
+			containers = append(containers, specContainers...)
 			if err != nil {
-				return results, err
+				return results, containers, err
 			}
-			results = append(results, result)
 		}
+		if container, ok := suite.getContainer(ginkgoReport); ok {
+			containers = append(containers, container)
+		}
+	}
+	return results, containers, nil
+}
+
+func newSuiteConverter(ginkgoReport types.Report, parserCreation parser.CreationFunc,
+	config parser.Config) *suiteConverter {
+	config.LabelsScraperOpts = append(append([]report.LabelsScraperOpt{}, config.LabelsScraperOpts...),
+		report.WithSuiteName(ginkgoReport.SuiteDescription), report.WithSuitePath(ginkgoReport.SuitePath),
+		report.WithSuiteLabels(ginkgoReport.SuiteLabels))
+	return &suiteConverter{
+		parserCreation: parserCreation,
+		config:         config,
+		container:      allure.Container{UUID: uuid.New()},
+		children:       map[uuid.UUID]bool{},
+		suiteLabels:    ginkgoReport.SuiteLabels,
+	}
+}
+
+// convertSpec converts It spec to results (with retries) and container with its fixtures.
+// Suite level nodes are collected as fixtures of the suite container.
+func (s *suiteConverter) convertSpec(specReport types.SpecReport) ([]allure.Result, []allure.Container, error) {
+	parse, err := s.prepareSpec(specReport)
+	if err != nil || parse == nil {
+		return nil, nil, err
+	}
+	output := parse()
+	if output.err != nil {
+		return nil, nil, output.err
+	}
+	s.addChild(output.result)
+	return append(output.retries, output.result), output.containers, nil
+}
+
+// prepareSpec collects suite level nodes and returns parsing of It spec, which doesn't
+// depend on the suite state and can be run concurrently.
+func (s *suiteConverter) prepareSpec(specReport types.SpecReport) (func() specOutput, error) {
+	if specReport.LeafNodeType.Is(types.NodeTypesForSuiteLevelNodes) {
+		fixture, err := GetSuiteFixture(specReport, s.config)
+		if err != nil {
+			return nil, err
+		}
+		if !specReport.LeafNodeType.Is(BeforeSuiteNodeTypes) {
+			s.container.Afters = append(s.container.Afters, fixture)
+			return nil, nil
+		}
+		s.container.Befores = append(s.container.Befores, fixture)
+		if s.setupFailure == nil && specReport.State.Is(types.SpecStateFailureStates) {
+			s.setupFailure = &specReport
+		}
+		return nil, nil
+	}
+	if specReport.LeafNodeType != types.NodeTypeIt {
+		return nil, nil
+	}
+	if s.config.SpecFilter != nil && !s.config.SpecFilter(s.suiteLabels, specReport) {
+		if s.summary != nil {
+			s.summary.AddFiltered()
+		}
+		return nil, nil
+	}
+	parserCreation, config, setupFailure := s.parserCreation, s.config, s.setupFailure
+	return func() specOutput {
+		return parseSpec(specReport, parserCreation, config, setupFailure)
+	}, nil
+}
+
+// addChild adds the result to the suite container once, results with the same UUID
+// (e.g. duplicates of several inputs) are the same child.
+func (s *suiteConverter) addChild(result allure.Result) {
+	if s.children[result.UUID] {
+		return
+	}
+	s.children[result.UUID] = true
+	s.container.Children = append(s.container.Children, result.UUID)
+}
+
+func parseSpec(specReport types.SpecReport, parserCreation parser.CreationFunc, config parser.Config,
+	setupFailure *types.SpecReport) specOutput {
+	p, err := parserCreation(specReport, config)
+	if err != nil {
+		return specOutput{err: err}
+	}
+	result, err := p.GetAllureReport()
+	if err != nil {
+		return specOutput{err: err}
+	}
+	if setupFailure != nil && result.Status != allure.Passed {
+		markSetupFailure(&result, setupFailure.LeafNodeType, *setupFailure)
+	} else if specReport.State.Is(types.SpecStateFailureStates) &&
+		specReport.Failure.FailureNodeType.Is(transform.BeforeEachNodeTypes) {
+		markSetupFailure(&result, specReport.Failure.FailureNodeType, specReport)
+	}
+	output := specOutput{result: result, retries: p.GetAllureRetries(result)}
+	if container, ok := p.GetAllureContainer(result); ok {
+		output.containers = append(output.containers, container)
+	}
+	return output
+}
+
+func (s *suiteConverter) getContainer(ginkgoReport types.Report) (allure.Container, bool) {
+	s.container.Start = ginkgoReport.StartTime.UnixMilli()
+	s.container.Stop = ginkgoReport.EndTime.UnixMilli()
+	return s.container, !s.container.IsEmpty()
+}
+
+// GetSuiteFixture converts suite level node (BeforeSuite, AfterSuite, etc.) to Allure fixture.
+func GetSuiteFixture(specReport types.SpecReport, config parser.Config) (*allure.Step, error) {
+	t := transform.NewTransform(config.TransformOpts...)
+	err := t.AnalyzeEvents(specReport.SpecEvents, specReport.Failure)
+	if err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("[%s] %s", specReport.LeafNodeType.String(), specReport.LeafNodeText)
+	return &allure.Step{
+		Name:   name,
+		Status: report.GetAllureStatus(specReport),
+		Start:  specReport.StartTime.UnixMilli(),
+		Stop:   specReport.EndTime.UnixMilli(),
+		Steps:  t.GetAllureSteps(),
+	}, nil
+}
+
+// markSetupFailure marks the result broken by failure of the setup node: suite level node
+// (BeforeSuite, etc.) or the spec's own before-each or before-all node.
+func markSetupFailure(result *allure.Result, setupNodeType types.NodeType, setupFailure types.SpecReport) {
+	statusDetails := report.GetStatusDetails(setupFailure)
+	result.Status = allure.Broken
+	result.StatusDetails = allure.StatusDetail{
+		Message: fmt.Sprintf("%s failed: %s", setupNodeType.String(), statusDetails.Message),
+		Trace:   statusDetails.Trace,
 	}
-	return results, nil
 }
 
 func PrintAllureReports(results []allure.Result, fm fmngr.FileManager) []error {
+	return PrintAllureReportsContext(context.Background(), results, fm)
+}
+
+// PrintAllureReportsContext stops between results when the context is done, the context
+// error is the last one.
+func PrintAllureReportsContext(ctx context.Context, results []allure.Result, fm fmngr.FileManager) []error {
 	errs := []error{}
 	for _, result := range results {
-		err := fm.SaveJSONResult(result)
+		if err := ctx.Err(); err != nil {
+			return append(errs, err)
+		}
+		errs = append(errs, printAllureReport(result, fm)...)
+	}
+	return errs
+}
+
+// removeAttachments removes saved attachments if the file manager implements
+// fmngr.AttachmentRemover.
+func removeAttachments(sources []string, fm fmngr.FileManager) []error {
+	remover, ok := fm.(fmngr.AttachmentRemover)
+	if !ok {
+		return nil
+	}
+	errs := []error{}
+	for _, source := range sources {
+		err := remover.RemoveAttachment(source)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func printAllureReport(result allure.Result, fm fmngr.FileManager) []error {
+	errs := []error{}
+	err := fm.SaveJSONResult(result)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, attachment := range GetAllAttachments(result) {
+		err = fm.SaveAttachment(attachment)
 		if err != nil {
 			errs = append(errs, err)
 		}
 	}
 	return errs
 }
+
+func PrintAllureContainers(containers []allure.Container, fm fmngr.FileManager) []error {
+	errs := []error{}
+	for _, container := range containers {
+		err := fm.SaveJSONContainer(container)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// PrintAllureMetadata saves categories, environment (suite information with user
+// properties) and executor files.
+func PrintAllureMetadata(ginkgoReports []types.Report, config metadata.Config, fm fmngr.FileManager) []error {
+	errs := []error{}
+	if len(config.Categories) != 0 {
+		err := fm.SaveCategories(config.Categories)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	properties := metadata.MergeProperties(metadata.GetSuiteProperties(ginkgoReports), config.Environment)
+	if len(properties) != 0 {
+		err := fm.SaveEnvironment(properties)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if config.Executor != nil {
+		err := fm.SaveExecutor(*config.Executor)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func GetAllAttachments(result allure.Result) []*allure.Attachment {
+	attachments := append([]*allure.Attachment{}, result.Attachments...)
+	return append(attachments, getStepsAttachments(result.Steps)...)
+}
+
+func getStepsAttachments(steps []*allure.Step) (attachments []*allure.Attachment) {
+	for _, step := range steps {
+		attachments = append(attachments, step.Attachments...)
+		attachments = append(attachments, getStepsAttachments(step.Steps)...)
+	}
+	return attachments
+}
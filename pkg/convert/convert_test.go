@@ -1,13 +1,17 @@
 package convert_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
 
 	"github.com/Moon1706/ginkgo2allure/pkg/convert"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/metadata"
 	"github.com/Moon1706/ginkgo2allure/pkg/convert/parser"
 	"github.com/Moon1706/ginkgo2allure/pkg/convert/report"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/transform"
+	"github.com/google/uuid"
 	"github.com/onsi/ginkgo/v2/types"
 	"github.com/ozontech/allure-go/pkg/allure"
 	"github.com/stretchr/testify/assert"
@@ -23,13 +27,19 @@ type (
 		Err error
 	}
 	mockFileManager struct {
-		SaveErr error
+		SaveErr           error
+		SaveAttachmentErr error
+		SaveContainerErr  error
+		SaveMetadataErr   error
 	}
 )
 
 func (m mockReport) GenerateAllureReport(_ []*allure.Step) (allure.Result, error) {
 	return allure.Result{}, nil
 }
+func (m mockReport) GenerateAllureRetries(_ allure.Result, _ [][]*allure.Step) []allure.Result {
+	return []allure.Result{}
+}
 func (m mockReport) SetLabelsScraper(_ report.LabelScraper) {}
 
 func (m mockTransform) AnalyzeEvents(_ types.SpecEvents, _ types.Failure) error {
@@ -38,9 +48,30 @@ func (m mockTransform) AnalyzeEvents(_ types.SpecEvents, _ types.Failure) error
 func (m mockTransform) GetAllureSteps() []*allure.Step {
 	return []*allure.Step{}
 }
+func (m mockTransform) GetAllureAttempts() [][]*allure.Step {
+	return [][]*allure.Step{}
+}
+func (m mockTransform) GetAllureFixtures() (befores, afters []*allure.Step) {
+	return nil, nil
+}
 func (m mockFileManager) SaveJSONResult(_ allure.Result) error {
 	return m.SaveErr
 }
+func (m mockFileManager) SaveAttachment(_ *allure.Attachment) error {
+	return m.SaveAttachmentErr
+}
+func (m mockFileManager) SaveJSONContainer(_ allure.Container) error {
+	return m.SaveContainerErr
+}
+func (m mockFileManager) SaveCategories(_ []metadata.Category) error {
+	return m.SaveMetadataErr
+}
+func (m mockFileManager) SaveEnvironment(_ []metadata.Property) error {
+	return m.SaveMetadataErr
+}
+func (m mockFileManager) SaveExecutor(_ metadata.Executor) error {
+	return m.SaveMetadataErr
+}
 
 func TestConvertGinkgoToAllureReport(t *testing.T) {
 	ginkgoReports := []types.Report{{
@@ -86,6 +117,108 @@ func TestConvertGinkgoToAllureReport(t *testing.T) {
 	}
 }
 
+func TestConvertSuiteFixtures(t *testing.T) {
+	ginkgoReports := []types.Report{{
+		SuiteDescription: "test",
+		SpecReports: types.SpecReports{types.SpecReport{
+			LeafNodeType: types.NodeTypeBeforeSuite,
+			State:        types.SpecStateFailed,
+			Failure: types.Failure{
+				Message: "setup error",
+			},
+		}, types.SpecReport{
+			LeafNodeType: types.NodeTypeIt,
+			LeafNodeText: "test",
+			State:        types.SpecStateSkipped,
+			SpecEvents: types.SpecEvents{{
+				SpecEventType:    types.SpecEventNodeStart,
+				NodeType:         types.NodeTypeBeforeEach,
+				CodeLocation:     types.CodeLocation{FileName: "test.go", LineNumber: 1},
+				TimelineLocation: types.TimelineLocation{Order: 1},
+			}, {
+				SpecEventType:    types.SpecEventNodeEnd,
+				NodeType:         types.NodeTypeBeforeEach,
+				CodeLocation:     types.CodeLocation{FileName: "test.go", LineNumber: 1},
+				TimelineLocation: types.TimelineLocation{Order: 2},
+			}},
+		}, types.SpecReport{
+			LeafNodeType: types.NodeTypeAfterSuite,
+			State:        types.SpecStatePassed,
+		}},
+	}}
+	config := parser.Config{
+		LabelsScraperOpts: []report.LabelsScraperOpt{report.WillAutoGenerateID(true)},
+	}
+	results, containers, err := convert.GinkgoToAllureReportWithContainers(context.Background(), ginkgoReports,
+		parser.NewDefaultParser, config)
+	assert.Empty(t, err, "no error during conversion")
+	assert.Len(t, results, 1, "only It specs converted to results")
+	assert.Equal(t, allure.Broken, results[0].Status, "spec marked broken due to BeforeSuite failure")
+	assert.Equal(t, "BeforeSuite failed: setup error", results[0].StatusDetails.Message, "setup failure message")
+
+	assert.Len(t, containers, 2, "spec and suite containers")
+	assert.Equal(t, []uuid.UUID{results[0].UUID}, containers[0].Children, "spec container children")
+	assert.Len(t, containers[0].Befores, 1, "spec container BeforeEach fixture")
+	assert.Equal(t, []uuid.UUID{results[0].UUID}, containers[1].Children, "suite container children")
+	assert.Len(t, containers[1].Befores, 1, "suite container BeforeSuite fixture")
+	assert.Equal(t, allure.Failed, containers[1].Befores[0].Status, "BeforeSuite fixture status")
+	assert.Len(t, containers[1].Afters, 1, "suite container AfterSuite fixture")
+	assert.Equal(t, allure.Passed, containers[1].Afters[0].Status, "AfterSuite fixture status")
+}
+
+func TestConvertBeforeEachFailure(t *testing.T) {
+	var tests = []struct {
+		name            string
+		failureNodeType types.NodeType
+		status          allure.Status
+		message         string
+	}{{
+		name:            "BeforeEach",
+		failureNodeType: types.NodeTypeBeforeEach,
+		status:          allure.Broken,
+		message:         "BeforeEach failed: setup error",
+	}, {
+		name:            "BeforeAll",
+		failureNodeType: types.NodeTypeBeforeAll,
+		status:          allure.Broken,
+		message:         "BeforeAll failed: setup error",
+	}, {
+		name:            "JustBeforeEach",
+		failureNodeType: types.NodeTypeJustBeforeEach,
+		status:          allure.Broken,
+		message:         "JustBeforeEach failed: setup error",
+	}, {
+		name:            "It",
+		failureNodeType: types.NodeTypeIt,
+		status:          allure.Failed,
+		message:         "setup error",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ginkgoReports := []types.Report{{
+				SuiteDescription: "test",
+				SpecReports: types.SpecReports{types.SpecReport{
+					LeafNodeType: types.NodeTypeIt,
+					LeafNodeText: "test",
+					State:        types.SpecStateFailed,
+					Failure: types.Failure{
+						Message:         "setup error",
+						FailureNodeType: tt.failureNodeType,
+					},
+				}},
+			}}
+			config := parser.Config{
+				LabelsScraperOpts: []report.LabelsScraperOpt{report.WillAutoGenerateID(true)},
+				TransformOpts:     []transform.Opt{transform.WillAnalyzeErrors(false, false)},
+			}
+			results, err := convert.GinkgoToAllureReport(ginkgoReports, parser.NewDefaultParser, config)
+			assert.Empty(t, err, "no error during conversion")
+			assert.Equal(t, tt.status, results[0].Status, "spec status")
+			assert.Equal(t, tt.message, results[0].StatusDetails.Message, "failure message")
+		})
+	}
+}
+
 func TestConvertPrintAllureReports(t *testing.T) {
 	var tests = []struct {
 		name            string
@@ -103,10 +236,64 @@ func TestConvertPrintAllureReports(t *testing.T) {
 			SaveErr: errTest,
 		},
 		errs: []error{errTest},
+	}, {
+		name: "wrong attachment",
+		mockFileManager: mockFileManager{
+			SaveAttachmentErr: errTest,
+		},
+		errs: []error{errTest, errTest},
 	}}
 
+	results := []allure.Result{{
+		Attachments: []*allure.Attachment{allure.NewAttachment("test", allure.Text, []byte("test"))},
+		Steps: []*allure.Step{{
+			Steps: []*allure.Step{{
+				Attachments: []*allure.Attachment{allure.NewAttachment("test", allure.Text, []byte("test"))},
+			}},
+		}},
+	}}
 	for _, tt := range tests {
-		errs := convert.PrintAllureReports([]allure.Result{{}}, tt.mockFileManager)
+		errs := convert.PrintAllureReports(results, tt.mockFileManager)
 		assert.Equal(t, tt.errs, errs, fmt.Sprintf("got expected errors (%s)", tt.name))
 	}
 }
+
+func TestConvertPrintAllureContainers(t *testing.T) {
+	errs := convert.PrintAllureContainers([]allure.Container{{}}, mockFileManager{})
+	assert.Equal(t, []error{}, errs, "no errors during containers saving")
+
+	errs = convert.PrintAllureContainers([]allure.Container{{}}, mockFileManager{SaveContainerErr: errTest})
+	assert.Equal(t, []error{errTest}, errs, "got expected errors")
+}
+
+func TestConvertPrintAllureMetadata(t *testing.T) {
+	ginkgoReports := []types.Report{{SuiteDescription: "test"}}
+	config := metadata.Config{
+		Categories: metadata.GetDefaultCategories(),
+		Executor:   &metadata.Executor{Name: "test"},
+	}
+	errs := convert.PrintAllureMetadata(ginkgoReports, config, mockFileManager{})
+	assert.Equal(t, []error{}, errs, "no errors during metadata saving")
+
+	errs = convert.PrintAllureMetadata(ginkgoReports, config, mockFileManager{SaveMetadataErr: errTest})
+	assert.Equal(t, []error{errTest, errTest, errTest}, errs, "got expected errors")
+
+	errs = convert.PrintAllureMetadata([]types.Report{}, metadata.Config{}, mockFileManager{SaveMetadataErr: errTest})
+	assert.Equal(t, []error{}, errs, "nothing to save")
+}
+
+func TestConvertContextCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ginkgoReports := []types.Report{{SpecReports: types.SpecReports{{LeafNodeType: types.NodeTypeIt}}}}
+	createFunc := func(specReport types.SpecReport, _ parser.Config) (*parser.Parser, error) {
+		return parser.NewParser(specReport, mockTransform{}, nil, mockReport{}), nil
+	}
+	results, err := convert.GinkgoToAllureReportContext(ctx, ginkgoReports, createFunc, parser.Config{})
+	assert.ErrorIs(t, err, context.Canceled, "conversion stopped")
+	assert.Empty(t, results, "no specs converted after cancellation")
+
+	errs := convert.PrintAllureReportsContext(ctx, []allure.Result{{}, {}}, mockFileManager{})
+	assert.Equal(t, []error{context.Canceled}, errs, "saving stopped")
+}
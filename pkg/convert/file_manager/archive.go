@@ -0,0 +1,202 @@
+package filemanager
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/pkg/errors"
+)
+
+const (
+	ArchiveFormatZip   = "zip"
+	ArchiveFormatTarGz = "tar.gz"
+)
+
+// ArchiveFileManager writes saved files into zip or tar.gz archive. Attachments are written as
+// soon as they are saved, so they aren't kept in memory. Results, containers and metadata are
+// kept until Save, so files saved several times (e.g. results with the same UUID) are written
+// once with the latest content. Attachments of replaced results can't be removed and stay in
+// the archive. The archive is written to a temp file next to the path and
+// renamed by Save.
+type ArchiveFileManager struct {
+	path    string
+	file    *os.File
+	zw      *zip.Writer
+	gw      *gzip.Writer
+	tw      *tar.Writer
+	closed  bool
+	written map[string]bool
+	pending map[string][]byte
+	mu      sync.Mutex
+	saver
+}
+
+// NewArchiveFileManager creates the temp file of the archive, the format is taken from
+// the path extension (see GetArchiveFormat).
+func NewArchiveFileManager(path string) (*ArchiveFileManager, error) {
+	format, err := GetArchiveFormat(path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), fmt.Sprintf(tempFilePattern, filepath.Base(path)))
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot create archive")
+	}
+	a := &ArchiveFileManager{path: path, file: file, written: map[string]bool{}, pending: map[string][]byte{}}
+	if format == ArchiveFormatZip {
+		a.zw = zip.NewWriter(file)
+	} else {
+		a.gw = gzip.NewWriter(file)
+		a.tw = tar.NewWriter(a.gw)
+	}
+	a.saver = saver{createUUIDFile: a.keepFile, createFile: a.keepFile}
+	return a, nil
+}
+
+// GetArchiveFormat returns archive format by the file extension.
+func GetArchiveFormat(path string) (string, error) {
+	switch {
+	case strings.HasSuffix(path, ".zip"):
+		return ArchiveFormatZip, nil
+	case strings.HasSuffix(path, ".tar.gz"), strings.HasSuffix(path, ".tgz"):
+		return ArchiveFormatTarGz, nil
+	}
+	return "", fmt.Errorf("unknown archive extension of %s, use .zip, .tar.gz or .tgz", path)
+}
+
+// SaveAttachment writes the attachment into the archive, the attachment with the same source
+// is written once.
+func (a *ArchiveFileManager) SaveAttachment(attachment *allure.Attachment) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return fmt.Errorf("archive %s is already closed", a.path)
+	}
+	if a.written[attachment.Source] {
+		return nil
+	}
+	err := a.addFile(attachment.Source, attachment.GetContent())
+	if err != nil {
+		return errors.Wrap(err, "Cannot save Attachment")
+	}
+	return nil
+}
+
+func (a *ArchiveFileManager) keepFile(name string, content []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return fmt.Errorf("archive %s is already closed", a.path)
+	}
+	a.pending[name] = content
+	return nil
+}
+
+func (a *ArchiveFileManager) addFile(name string, content []byte) error {
+	a.written[name] = true
+	modified := time.Now()
+	if a.zw != nil {
+		fw, err := a.zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return errors.Wrap(err, "Cannot write archive")
+		}
+		_, err = fw.Write(content)
+		return errors.Wrap(err, "Cannot write archive")
+	}
+	err := a.tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    fileSystemPermissionCode,
+		Size:    int64(len(content)),
+		ModTime: modified,
+	})
+	if err != nil {
+		return errors.Wrap(err, "Cannot write archive")
+	}
+	_, err = a.tw.Write(content)
+	return errors.Wrap(err, "Cannot write archive")
+}
+
+// Save finishes the archive and renames the temp file to the path, so the path never
+// contains a partially written archive. Files can't be saved after that.
+func (a *ArchiveFileManager) Save() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return fmt.Errorf("archive %s is already closed", a.path)
+	}
+	err := a.addPendingFiles()
+	if closeErr := a.close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(a.file.Name(), fileSystemPermissionCode)
+	}
+	if err == nil {
+		err = os.Rename(a.file.Name(), a.path)
+	}
+	if err != nil {
+		_ = os.Remove(a.file.Name())
+		return errors.Wrap(err, "Cannot save archive")
+	}
+	return nil
+}
+
+// Cleanup removes the temp file of not saved archive, files can't be saved after that. It does
+// nothing after Save.
+func (a *ArchiveFileManager) Cleanup() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.closed {
+		_ = a.close()
+	}
+	err := os.Remove(a.file.Name())
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "Cannot remove archive")
+	}
+	return nil
+}
+
+func (a *ArchiveFileManager) addPendingFiles() error {
+	names := make([]string, 0, len(a.pending))
+	for name := range a.pending {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		err := a.addFile(name, a.pending[name])
+		if err != nil {
+			return err
+		}
+	}
+	a.pending = map[string][]byte{}
+	return nil
+}
+
+func (a *ArchiveFileManager) close() error {
+	a.closed = true
+	var err error
+	if a.zw != nil {
+		err = a.zw.Close()
+	} else {
+		err = a.tw.Close()
+		if err == nil {
+			err = a.gw.Close()
+		}
+	}
+	if err == nil {
+		err = a.file.Sync()
+	}
+	if closeErr := a.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
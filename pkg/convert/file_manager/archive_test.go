@@ -0,0 +1,136 @@
+package filemanager_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	fmngr "github.com/Moon1706/ginkgo2allure/pkg/convert/file_manager"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/metadata"
+	"github.com/google/uuid"
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/stretchr/testify/assert"
+)
+
+func readZip(t *testing.T, content []byte) map[string]string {
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	assert.Empty(t, err, "zip archive read")
+	files := map[string]string{}
+	for _, file := range zr.File {
+		rc, err := file.Open()
+		assert.Empty(t, err, "zip file opened")
+		bFile, err := io.ReadAll(rc)
+		assert.Empty(t, err, "zip file read")
+		assert.NotContains(t, files, file.Name, "zip file written once")
+		files[file.Name] = string(bFile)
+	}
+	return files
+}
+
+func readTarGz(t *testing.T, content []byte) map[string]string {
+	gr, err := gzip.NewReader(bytes.NewReader(content))
+	assert.Empty(t, err, "gzip archive read")
+	tr := tar.NewReader(gr)
+	files := map[string]string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		assert.Empty(t, err, "tar header read")
+		bFile, err := io.ReadAll(tr)
+		assert.Empty(t, err, "tar file read")
+		assert.NotContains(t, files, header.Name, "tar file written once")
+		files[header.Name] = string(bFile)
+	}
+}
+
+func TestArchiveFileManager(t *testing.T) {
+	var tests = []struct {
+		format string
+		read   func(t *testing.T, content []byte) map[string]string
+	}{{
+		format: fmngr.ArchiveFormatZip,
+		read:   readZip,
+	}, {
+		format: fmngr.ArchiveFormatTarGz,
+		read:   readTarGz,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "allure-results."+tt.format)
+			fm, err := fmngr.NewArchiveFileManager(path)
+			assert.Empty(t, err, "archive file manager created")
+			id := uuid.New()
+			attachment := allure.NewAttachment("test", allure.Text, []byte("test"))
+			assert.Empty(t, fm.SaveJSONResult(allure.Result{UUID: id, Name: "first"}), "result saved")
+			assert.Empty(t, fm.SaveJSONResult(allure.Result{UUID: id, Name: "second"}), "result saved again")
+			assert.Empty(t, fm.SaveAttachment(attachment), "attachment saved")
+			assert.Empty(t, fm.SaveAttachment(attachment), "attachment saved again")
+			assert.Empty(t, fm.SaveEnvironment([]metadata.Property{{Key: "a", Value: "b"}}), "environment saved")
+			_, err = os.Stat(path)
+			assert.True(t, os.IsNotExist(err), "archive isn't at the path before saving")
+
+			assert.Empty(t, fm.Save(), "archive saved")
+			content, err := os.ReadFile(path)
+			assert.Empty(t, err, "archive read")
+			files := tt.read(t, content)
+			assert.Len(t, files, 3, "saved archive contains files")
+			assert.Contains(t, files[id.String()+"-result.json"], `"name":"second"`, "latest result extracted")
+			assert.Equal(t, "test", files[attachment.Source], "attachment written")
+			assert.Equal(t, "a=b\n", files[metadata.EnvironmentFileName], "environment written")
+			assert.Error(t, fm.SaveAttachment(attachment), "archive closed")
+			assert.Error(t, fm.Save(), "archive saved once")
+			entries, err := os.ReadDir(dir)
+			assert.Empty(t, err, "archive folder read")
+			assert.Len(t, entries, 1, "only archive left")
+		})
+	}
+
+	_, err := fmngr.NewArchiveFileManager(filepath.Join(t.TempDir(), "results.rar"))
+	assert.Error(t, err, "unknown format")
+}
+
+func TestArchiveFileManagerCleanup(t *testing.T) {
+	dir := t.TempDir()
+	fm, err := fmngr.NewArchiveFileManager(filepath.Join(dir, "allure-results.zip"))
+	assert.Empty(t, err, "archive file manager created")
+	assert.Empty(t, fm.SaveJSONResult(allure.Result{UUID: uuid.New()}), "result saved")
+	assert.Empty(t, fm.Cleanup(), "archive dropped")
+	entries, err := os.ReadDir(dir)
+	assert.Empty(t, err, "archive folder read")
+	assert.Empty(t, entries, "temp file removed")
+	assert.Error(t, fm.SaveJSONResult(allure.Result{UUID: uuid.New()}), "archive closed")
+
+	path := filepath.Join(dir, "allure-results.tgz")
+	fm, err = fmngr.NewArchiveFileManager(path)
+	assert.Empty(t, err, "archive file manager created")
+	assert.Empty(t, fm.Save(), "archive saved")
+	assert.Empty(t, fm.Cleanup(), "nothing to remove after saving")
+	_, err = os.Stat(path)
+	assert.Empty(t, err, "saved archive kept")
+}
+
+func TestGetArchiveFormat(t *testing.T) {
+	var tests = []struct {
+		path   string
+		format string
+		err    bool
+	}{
+		{path: "results.zip", format: fmngr.ArchiveFormatZip},
+		{path: "results.tar.gz", format: fmngr.ArchiveFormatTarGz},
+		{path: "results.tgz", format: fmngr.ArchiveFormatTarGz},
+		{path: "results.tar", err: true},
+	}
+	for _, tt := range tests {
+		format, err := fmngr.GetArchiveFormat(tt.path)
+		assert.Equal(t, tt.format, format, tt.path)
+		assert.Equal(t, tt.err, err != nil, tt.path)
+	}
+}
@@ -1,46 +1,268 @@
 package filemanager
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"sync"
 
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/metadata"
 	"github.com/ozontech/allure-go/pkg/allure"
 	"github.com/pkg/errors"
 )
 
 const (
 	fileSystemPermissionCode = 0644
+	tempFilePattern          = ".%s.*.tmp"
+
+	DefaultManifestFileName = "ginkgo2allure-manifest.json"
 )
 
+// FileManager is the sink of the conversion: it receives results, containers, attachments
+// and metadata. Use NewFileManager to save them to a folder, NewMemoryFileManager to keep
+// them in memory, NewJUnitFileManager to write JUnit XML report and NewMultiFileManager
+// to send them to several file managers.
 type FileManager interface {
 	SaveJSONResult(result allure.Result) error
+	SaveAttachment(attachment *allure.Attachment) error
+	SaveJSONContainer(container allure.Container) error
+	SaveCategories(categories []metadata.Category) error
+	SaveEnvironment(properties []metadata.Property) error
+	SaveExecutor(executor metadata.Executor) error
+}
+
+// Cleaner is implemented by file managers which can remove files written by them.
+type Cleaner interface {
+	Cleanup() error
+}
+
+// AttachmentRemover is implemented by file managers which can remove saved attachments, e.g.
+// of results replaced by the latest results with the same UUID.
+type AttachmentRemover interface {
+	RemoveAttachment(source string) error
+}
+
+// FileLister is implemented by file managers which can list names of files written by them.
+type FileLister interface {
+	GetFileNames() []string
+}
+
+// ManifestSaver is implemented by file managers which can save manifest of written files.
+type ManifestSaver interface {
+	SaveManifest() error
+}
+
+type (
+	// Manifest lists all files written to the results folder, so the upload can check
+	// that the folder is complete.
+	Manifest struct {
+		Files []ManifestFile `json:"files"`
+	}
+	ManifestFile struct {
+		Name   string `json:"name"`
+		Size   int    `json:"size"`
+		SHA256 string `json:"sha256"`
+	}
+	fileManager struct {
+		resultsFolderPath  string
+		ctx                context.Context
+		manifestFileName   string
+		createFolder       bool
+		cleanFolder        bool
+		requireEmptyFolder bool
+		detectCollisions   bool
+		collisionsOnCreate bool
+		written            []string
+		manifest           map[string]ManifestFile
+		savedBefore        map[string]bool
+		writtenMu          sync.Mutex
+		saver
+	}
+	Opt func(m *fileManager)
+)
+
+// WithContext stops saving files when the context is done. Files which are being saved
+// are written completely.
+func WithContext(ctx context.Context) Opt {
+	return func(m *fileManager) {
+		m.ctx = ctx
+	}
+}
+
+// WithManifest enables saving of the manifest with SaveManifest.
+func WithManifest(fileName string) Opt {
+	return func(m *fileManager) {
+		m.manifestFileName = fileName
+	}
 }
 
-type fileManager struct {
-	resultsFolderPath string
+// WithSavedFiles sets names of files saved to the results folder before, e.g. by the watch
+// before restart. They are overwritten even if collisions are detected.
+func WithSavedFiles(names []string) Opt {
+	return func(m *fileManager) {
+		for _, name := range names {
+			m.savedBefore[name] = true
+		}
+	}
 }
 
-func NewFileManager(resultsFolderPath string) FileManager {
-	return &fileManager{
+// NewFileManager creates file manager and prepares the results folder according to options.
+func NewFileManager(resultsFolderPath string, opts ...Opt) (FileManager, error) {
+	m := &fileManager{
 		resultsFolderPath: resultsFolderPath,
+		ctx:               context.Background(),
+		manifest:          map[string]ManifestFile{},
+		savedBefore:       map[string]bool{},
 	}
+	m.saver = saver{createUUIDFile: m.createUUIDFile, createFile: m.createFile}
+	for _, opt := range opts {
+		opt(m)
+	}
+	err := m.prepareFolder()
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
 }
 
+// createUUIDFile creates file named by UUID of result, container or attachment.
+func (m *fileManager) createUUIDFile(name string, content []byte) error {
+	err := m.checkCollision(name)
+	if err != nil {
+		return err
+	}
+	return m.createFile(name, content)
+}
+
+// createFile writes content to a temp file in the results folder and renames it, so
+// the folder never contains partially written files.
 func (m *fileManager) createFile(name string, content []byte) error {
-	return os.WriteFile(filepath.Join(m.resultsFolderPath, name), content, fileSystemPermissionCode)
+	if err := m.ctx.Err(); err != nil {
+		return err
+	}
+	path := filepath.Join(m.resultsFolderPath, name)
+	err := writeFileAtomic(path, content)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(content)
+	m.writtenMu.Lock()
+	defer m.writtenMu.Unlock()
+	m.written = append(m.written, path)
+	m.manifest[name] = ManifestFile{Name: name, Size: len(content), SHA256: hex.EncodeToString(sum[:])}
+	return nil
 }
 
-func (m *fileManager) SaveJSONResult(result allure.Result) error {
-	bResult, err := json.Marshal(result)
+func writeFileAtomic(path string, content []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), fmt.Sprintf(tempFilePattern, filepath.Base(path)))
 	if err != nil {
-		return errors.Wrap(err, "Failed marshal Result")
+		return err
 	}
+	tempPath := file.Name()
+	defer os.Remove(tempPath)
 
-	err = m.createFile(fmt.Sprintf("%s-result.json", result.UUID), bResult)
+	_, err = file.Write(content)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	err = os.Chmod(tempPath, fileSystemPermissionCode)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tempPath, path)
+}
+
+// SaveManifest saves manifest of all files written before. It does nothing if the manifest
+// is not enabled with WithManifest.
+func (m *fileManager) SaveManifest() error {
+	if m.manifestFileName == "" {
+		return nil
+	}
+	m.writtenMu.Lock()
+	manifest := Manifest{Files: make([]ManifestFile, 0, len(m.manifest))}
+	for _, file := range m.manifest {
+		if file.Name == m.manifestFileName {
+			continue
+		}
+		manifest.Files = append(manifest.Files, file)
+	}
+	m.writtenMu.Unlock()
+	sort.Slice(manifest.Files, func(i, j int) bool { return manifest.Files[i].Name < manifest.Files[j].Name })
+
+	bManifest, err := json.Marshal(manifest)
+	if err != nil {
+		return errors.Wrap(err, "Failed marshal Manifest")
+	}
+	err = m.createFile(m.manifestFileName, bManifest)
 	if err != nil {
-		return errors.Wrap(err, "Cannot save Result")
+		return errors.Wrap(err, "Cannot save Manifest")
+	}
+	return nil
+}
+
+// RemoveAttachment removes the attachment written by the file manager, attachments which
+// weren't written are left.
+func (m *fileManager) RemoveAttachment(source string) error {
+	path := filepath.Join(m.resultsFolderPath, source)
+	m.writtenMu.Lock()
+	defer m.writtenMu.Unlock()
+	if _, ok := m.manifest[source]; !ok {
+		return nil
+	}
+	err := os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "Cannot remove Attachment")
+	}
+	delete(m.manifest, source)
+	for i, written := range m.written {
+		if written == path {
+			m.written = append(m.written[:i], m.written[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// GetFileNames returns names of files saved before (see WithSavedFiles) and written by the
+// file manager.
+func (m *fileManager) GetFileNames() []string {
+	m.writtenMu.Lock()
+	defer m.writtenMu.Unlock()
+	names := make([]string, 0, len(m.savedBefore)+len(m.manifest))
+	for name := range m.savedBefore {
+		names = append(names, name)
+	}
+	for name := range m.manifest {
+		if !m.savedBefore[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Cleanup removes all files written by the file manager, e.g. after interrupted conversion.
+func (m *fileManager) Cleanup() error {
+	m.writtenMu.Lock()
+	defer m.writtenMu.Unlock()
+	for _, path := range m.written {
+		err := os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "Cannot remove file")
+		}
 	}
+	m.written = nil
+	m.manifest = map[string]ManifestFile{}
 	return nil
 }
@@ -1,12 +1,18 @@
 package filemanager_test
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"testing"
 
 	fmngr "github.com/Moon1706/ginkgo2allure/pkg/convert/file_manager"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/metadata"
 	"github.com/google/uuid"
 	"github.com/ozontech/allure-go/pkg/allure"
 	"github.com/stretchr/testify/assert"
@@ -19,9 +25,228 @@ func TestFileManagerSuccess(t *testing.T) {
 	err := os.MkdirAll(resultsPath, os.ModePerm)
 	assert.Empty(t, err, "tmp test dir should create successful")
 
-	fm := fmngr.NewFileManager(resultsPath)
+	fm := newFileManager(t, resultsPath)
 	err = fm.SaveJSONResult(allure.Result{
 		UUID: id,
 	})
 	assert.Empty(t, err, "report saved successful")
 }
+
+func TestFileManagerSaveAttachment(t *testing.T) {
+	resultsPath := filepath.Join(os.TempDir(), fmt.Sprintf("test-%s", uuid.New().String()))
+	err := os.MkdirAll(resultsPath, os.ModePerm)
+	assert.Empty(t, err, "tmp test dir should create successful")
+
+	fm := newFileManager(t, resultsPath)
+	attachment := allure.NewAttachment("test", allure.Text, []byte("test"))
+	err = fm.SaveAttachment(attachment)
+	assert.Empty(t, err, "attachment saved successful")
+
+	content, err := os.ReadFile(filepath.Join(resultsPath, attachment.Source))
+	assert.Empty(t, err, "attachment file exists")
+	assert.Equal(t, []byte("test"), content, "attachment content saved")
+}
+
+func TestFileManagerRemoveAttachment(t *testing.T) {
+	resultsPath := t.TempDir()
+	fm := newFileManager(t, resultsPath)
+	attachment := allure.NewAttachment("test", allure.Text, []byte("test"))
+	assert.Empty(t, fm.SaveAttachment(attachment), "attachment saved")
+	assert.Empty(t, fm.(fmngr.AttachmentRemover).RemoveAttachment(attachment.Source), "attachment removed")
+	_, err := os.Stat(filepath.Join(resultsPath, attachment.Source))
+	assert.True(t, os.IsNotExist(err), "attachment file removed")
+	assert.Empty(t, fm.(fmngr.FileLister).GetFileNames(), "attachment isn't listed")
+}
+
+func TestFileManagerSaveJSONContainer(t *testing.T) {
+	resultsPath := filepath.Join(os.TempDir(), fmt.Sprintf("test-%s", uuid.New().String()))
+	err := os.MkdirAll(resultsPath, os.ModePerm)
+	assert.Empty(t, err, "tmp test dir should create successful")
+
+	fm := newFileManager(t, resultsPath)
+	container := allure.Container{UUID: uuid.New()}
+	err = fm.SaveJSONContainer(container)
+	assert.Empty(t, err, "container saved successful")
+	_, err = os.Stat(filepath.Join(resultsPath, fmt.Sprintf("%s-container.json", container.UUID)))
+	assert.Empty(t, err, "container file exists")
+}
+
+func TestFileManagerSaveCategories(t *testing.T) {
+	resultsPath := filepath.Join(os.TempDir(), fmt.Sprintf("test-%s", uuid.New().String()))
+	err := os.MkdirAll(resultsPath, os.ModePerm)
+	assert.Empty(t, err, "tmp test dir should create successful")
+
+	fm := newFileManager(t, resultsPath)
+	err = fm.SaveCategories(metadata.GetDefaultCategories())
+	assert.Empty(t, err, "categories saved successful")
+	_, err = os.Stat(filepath.Join(resultsPath, metadata.CategoriesFileName))
+	assert.Empty(t, err, "categories file exists")
+}
+
+func TestFileManagerSaveEnvironmentAndExecutor(t *testing.T) {
+	resultsPath := filepath.Join(os.TempDir(), fmt.Sprintf("test-%s", uuid.New().String()))
+	err := os.MkdirAll(resultsPath, os.ModePerm)
+	assert.Empty(t, err, "tmp test dir should create successful")
+
+	fm := newFileManager(t, resultsPath)
+	err = fm.SaveEnvironment([]metadata.Property{{Key: "cluster", Value: "kind"}})
+	assert.Empty(t, err, "environment saved successful")
+	content, err := os.ReadFile(filepath.Join(resultsPath, metadata.EnvironmentFileName))
+	assert.Empty(t, err, "environment file exists")
+	assert.Equal(t, "cluster=kind\n", string(content), "environment content")
+
+	err = fm.SaveExecutor(metadata.Executor{Name: "Jenkins"})
+	assert.Empty(t, err, "executor saved successful")
+	content, err = os.ReadFile(filepath.Join(resultsPath, metadata.ExecutorFileName))
+	assert.Empty(t, err, "executor file exists")
+	assert.Equal(t, `{"name":"Jenkins"}`, string(content), "executor content")
+}
+
+func TestFileManagerContextAndCleanup(t *testing.T) {
+	resultsPath := t.TempDir()
+	existing := filepath.Join(resultsPath, "existing.json")
+	err := os.WriteFile(existing, []byte("{}"), 0644)
+	assert.Empty(t, err, "existing file created")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fm := newFileManager(t, resultsPath, fmngr.WithContext(ctx))
+	err = fm.SaveJSONResult(allure.Result{UUID: uuid.New()})
+	assert.Empty(t, err, "result saved before cancellation")
+
+	cancel()
+	err = fm.SaveJSONContainer(allure.Container{UUID: uuid.New()})
+	assert.ErrorIs(t, err, context.Canceled, "nothing saved after cancellation")
+
+	cleaner, ok := fm.(fmngr.Cleaner)
+	assert.True(t, ok, "file manager removes written files")
+	assert.Empty(t, cleaner.Cleanup(), "written files removed")
+	entries, err := os.ReadDir(resultsPath)
+	assert.Empty(t, err, "results folder read")
+	assert.Len(t, entries, 1, "only existing file kept")
+}
+
+func TestFileManagerAtomicWrites(t *testing.T) {
+	resultsPath := t.TempDir()
+	fm := newFileManager(t, resultsPath)
+	id := uuid.New()
+	for _, name := range []string{"first", "second"} {
+		err := fm.SaveJSONResult(allure.Result{UUID: id, Name: name})
+		assert.Empty(t, err, "result saved")
+	}
+
+	entries, err := os.ReadDir(resultsPath)
+	assert.Empty(t, err, "results folder read")
+	assert.Len(t, entries, 1, "no temp files left")
+	info, err := entries[0].Info()
+	assert.Empty(t, err, "file info read")
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm(), "file permissions")
+	content, err := os.ReadFile(filepath.Join(resultsPath, fmt.Sprintf("%s-result.json", id)))
+	assert.Empty(t, err, "result read")
+	assert.Contains(t, string(content), `"name":"second"`, "result replaced")
+
+	err = newFileManager(t, filepath.Join(resultsPath, "missing")).SaveJSONResult(allure.Result{})
+	assert.Error(t, err, "results folder doesn't exist")
+}
+
+func TestFileManagerSaveManifest(t *testing.T) {
+	resultsPath := t.TempDir()
+	fm := newFileManager(t, resultsPath, fmngr.WithManifest(fmngr.DefaultManifestFileName))
+	attachment := allure.NewAttachment("test", allure.Text, []byte("test"))
+	assert.Empty(t, fm.SaveAttachment(attachment), "attachment saved")
+	assert.Empty(t, fm.SaveExecutor(metadata.Executor{Name: "CI"}), "executor saved")
+
+	saver, ok := fm.(fmngr.ManifestSaver)
+	assert.True(t, ok, "file manager saves manifest")
+	assert.Empty(t, saver.SaveManifest(), "manifest saved")
+
+	content, err := os.ReadFile(filepath.Join(resultsPath, fmngr.DefaultManifestFileName))
+	assert.Empty(t, err, "manifest read")
+	manifest := fmngr.Manifest{}
+	assert.Empty(t, json.Unmarshal(content, &manifest), "manifest unmarshaled")
+	executorSum := sha256.Sum256([]byte(`{"name":"CI"}`))
+	assert.ElementsMatch(t, []fmngr.ManifestFile{{
+		Name:   attachment.Source,
+		Size:   4,
+		SHA256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+	}, {
+		Name:   metadata.ExecutorFileName,
+		Size:   len(`{"name":"CI"}`),
+		SHA256: hex.EncodeToString(executorSum[:]),
+	}}, manifest.Files, "written files listed")
+	assert.True(t, sort.SliceIsSorted(manifest.Files, func(i, j int) bool {
+		return manifest.Files[i].Name < manifest.Files[j].Name
+	}), "files sorted by name")
+
+	fm = newFileManager(t, t.TempDir())
+	assert.Empty(t, fm.(fmngr.ManifestSaver).SaveManifest(), "manifest disabled")
+}
+
+func newFileManager(t *testing.T, resultsPath string, opts ...fmngr.Opt) fmngr.FileManager {
+	fm, err := fmngr.NewFileManager(resultsPath, opts...)
+	assert.Empty(t, err, "file manager created")
+	return fm
+}
+
+func TestFileManagerFolder(t *testing.T) {
+	resultsPath := filepath.Join(t.TempDir(), "allure", "results")
+	_, err := fmngr.NewFileManager(resultsPath, fmngr.WillRequireEmptyFolder(true))
+	assert.Empty(t, err, "missing folder is empty")
+	newFileManager(t, resultsPath, fmngr.WillCreateFolder(true))
+	assert.DirExists(t, resultsPath, "folder created")
+
+	stale := filepath.Join(resultsPath, "stale-result.json")
+	assert.Empty(t, os.WriteFile(stale, []byte("{}"), 0644), "stale result created")
+	_, err = fmngr.NewFileManager(resultsPath, fmngr.WillRequireEmptyFolder(true))
+	assert.Error(t, err, "folder is not empty")
+
+	newFileManager(t, resultsPath, fmngr.WillCleanFolder(true), fmngr.WillRequireEmptyFolder(true))
+	assert.NoFileExists(t, stale, "folder cleaned")
+}
+
+func TestFileManagerCollisions(t *testing.T) {
+	resultsPath := t.TempDir()
+	id := uuid.New()
+	assert.Empty(t, newFileManager(t, resultsPath).SaveJSONResult(allure.Result{UUID: id}),
+		"result of previous run saved")
+
+	fm := newFileManager(t, resultsPath, fmngr.WillDetectCollisions(true))
+	assert.Error(t, fm.SaveJSONResult(allure.Result{UUID: id}), "result of previous run not overwritten")
+
+	id = uuid.New()
+	assert.Empty(t, fm.SaveJSONResult(allure.Result{UUID: id}), "new result saved")
+	assert.Empty(t, fm.SaveJSONResult(allure.Result{UUID: id}), "own result overwritten")
+	assert.Empty(t, fm.SaveCategories(nil), "metadata saved")
+	assert.Empty(t, fm.SaveCategories(nil), "metadata overwritten")
+}
+
+func TestFileManagerCollisionsOnCreate(t *testing.T) {
+	resultsPath := t.TempDir()
+	id := uuid.New()
+	fm := newFileManager(t, resultsPath)
+	assert.Empty(t, fm.SaveCategories(nil), "metadata of previous run saved")
+	assert.Empty(t, newFileManager(t, resultsPath, fmngr.WillDetectCollisionsOnCreate(true)).SaveCategories(nil),
+		"folder with metadata accepted")
+
+	assert.Empty(t, fm.SaveJSONResult(allure.Result{UUID: id}), "result of previous run saved")
+	_, err := fmngr.NewFileManager(resultsPath, fmngr.WillDetectCollisionsOnCreate(true))
+	assert.Error(t, err, "folder with results of previous run refused")
+	names := fm.(fmngr.FileLister).GetFileNames()
+	fm = newFileManager(t, resultsPath, fmngr.WillDetectCollisionsOnCreate(true), fmngr.WithSavedFiles(names))
+	assert.Empty(t, fm.SaveJSONResult(allure.Result{UUID: id}), "saved result overwritten")
+	newFileManager(t, resultsPath, fmngr.WillDetectCollisionsOnCreate(true), fmngr.WillCleanFolder(true))
+}
+
+func TestFileManagerSavedFiles(t *testing.T) {
+	resultsPath := t.TempDir()
+	previous, next := uuid.New(), uuid.New()
+	fm := newFileManager(t, resultsPath)
+	assert.Empty(t, fm.SaveJSONResult(allure.Result{UUID: previous}), "result saved before restart")
+	names := fm.(fmngr.FileLister).GetFileNames()
+	assert.Equal(t, []string{previous.String() + "-result.json"}, names, "saved file listed")
+
+	fm = newFileManager(t, resultsPath, fmngr.WillDetectCollisions(true), fmngr.WithSavedFiles(names))
+	assert.Empty(t, fm.SaveJSONResult(allure.Result{UUID: previous}), "result saved before overwritten")
+	assert.Empty(t, fm.SaveJSONResult(allure.Result{UUID: next}), "new result saved")
+	assert.ElementsMatch(t, []string{previous.String() + "-result.json", next.String() + "-result.json"},
+		fm.(fmngr.FileLister).GetFileNames(), "saved and written files listed")
+}
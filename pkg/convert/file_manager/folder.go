@@ -0,0 +1,121 @@
+package filemanager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	folderPermissionCode = 0755
+
+	resultFileSuffix    = "-result.json"
+	containerFileSuffix = "-container.json"
+	attachmentFileInfix = "-attachment"
+)
+
+// WillCreateFolder creates the results folder (with parents) if it doesn't exist.
+func WillCreateFolder(create bool) Opt {
+	return func(m *fileManager) {
+		m.createFolder = create
+	}
+}
+
+// WillCleanFolder removes everything from the results folder before saving.
+func WillCleanFolder(clean bool) Opt {
+	return func(m *fileManager) {
+		m.cleanFolder = clean
+	}
+}
+
+// WillRequireEmptyFolder refuses to save into the results folder with files.
+func WillRequireEmptyFolder(requireEmpty bool) Opt {
+	return func(m *fileManager) {
+		m.requireEmptyFolder = requireEmpty
+	}
+}
+
+// WillDetectCollisions refuses to overwrite results, containers and attachments which
+// were not saved by this file manager, e.g. results of a previous run with the same UUID.
+func WillDetectCollisions(detect bool) Opt {
+	return func(m *fileManager) {
+		m.detectCollisions = detect
+	}
+}
+
+// WillDetectCollisionsOnCreate refuses the results folder with results, containers and
+// attachments which were not saved before (see WithSavedFiles). The folder is checked once when
+// the file manager is created, so nothing is written to the folder of another run.
+func WillDetectCollisionsOnCreate(detect bool) Opt {
+	return func(m *fileManager) {
+		m.collisionsOnCreate = detect
+	}
+}
+
+func (m *fileManager) prepareFolder() error {
+	if m.createFolder {
+		err := os.MkdirAll(m.resultsFolderPath, folderPermissionCode)
+		if err != nil {
+			return errors.Wrap(err, "Cannot create results folder")
+		}
+	}
+	if !m.cleanFolder && !m.requireEmptyFolder && !m.collisionsOnCreate {
+		return nil
+	}
+	entries, err := os.ReadDir(m.resultsFolderPath)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "Cannot read results folder")
+	}
+	if m.cleanFolder {
+		for _, entry := range entries {
+			err = os.RemoveAll(filepath.Join(m.resultsFolderPath, entry.Name()))
+			if err != nil {
+				return errors.Wrap(err, "Cannot clean results folder")
+			}
+		}
+		return nil
+	}
+	if m.requireEmptyFolder && len(entries) != 0 {
+		return fmt.Errorf("results folder %s is not empty", m.resultsFolderPath)
+	}
+	if !m.collisionsOnCreate {
+		return nil
+	}
+	for _, entry := range entries {
+		if isUUIDFile(entry.Name()) && !m.savedBefore[entry.Name()] {
+			return fmt.Errorf("results folder %s already contains %s of another run", m.resultsFolderPath,
+				entry.Name())
+		}
+	}
+	return nil
+}
+
+// isUUIDFile checks whether the file is result, container or attachment named by UUID.
+func isUUIDFile(name string) bool {
+	return strings.HasSuffix(name, resultFileSuffix) || strings.HasSuffix(name, containerFileSuffix) ||
+		strings.Contains(name, attachmentFileInfix)
+}
+
+func (m *fileManager) checkCollision(name string) error {
+	if !m.detectCollisions || m.collisionsOnCreate {
+		return nil
+	}
+	m.writtenMu.Lock()
+	_, written := m.manifest[name]
+	written = written || m.savedBefore[name]
+	m.writtenMu.Unlock()
+	if written {
+		return nil
+	}
+	_, err := os.Stat(filepath.Join(m.resultsFolderPath, name))
+	if err == nil {
+		return fmt.Errorf("file %s already exists in results folder", name)
+	}
+	if !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package filemanager
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/metadata"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/report"
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/pkg/errors"
+)
+
+const (
+	junitStepSeparator = " > "
+	junitIndent        = "  "
+)
+
+type (
+	// JUnitFileManager collects results and writes them as JUnit XML report, so CI test tabs
+	// show the same labels and statuses as Allure. Results are grouped into test suites by
+	// the suite label, retries (results with the same history ID) are reported once with
+	// the latest attempt. Other files are ignored.
+	JUnitFileManager struct {
+		results map[string]junitResult
+		mu      sync.Mutex
+	}
+	// junitResult keeps only fields of the result which are written to the report, steps and
+	// attachments aren't kept.
+	junitResult struct {
+		name        string
+		suite       string
+		status      allure.Status
+		start       int64
+		stop        int64
+		properties  []junitProperty
+		message     string
+		trace       string
+		failedSteps []string
+	}
+	junitTestSuites struct {
+		XMLName    xml.Name         `xml:"testsuites"`
+		Tests      int              `xml:"tests,attr"`
+		Failures   int              `xml:"failures,attr"`
+		Errors     int              `xml:"errors,attr"`
+		Skipped    int              `xml:"skipped,attr"`
+		Time       float64          `xml:"time,attr"`
+		TestSuites []junitTestSuite `xml:"testsuite"`
+	}
+	junitTestSuite struct {
+		Name      string          `xml:"name,attr"`
+		Tests     int             `xml:"tests,attr"`
+		Failures  int             `xml:"failures,attr"`
+		Errors    int             `xml:"errors,attr"`
+		Skipped   int             `xml:"skipped,attr"`
+		Time      float64         `xml:"time,attr"`
+		TestCases []junitTestCase `xml:"testcase"`
+	}
+	junitTestCase struct {
+		Name       string          `xml:"name,attr"`
+		Classname  string          `xml:"classname,attr"`
+		Status     string          `xml:"status,attr"`
+		Time       float64         `xml:"time,attr"`
+		Properties []junitProperty `xml:"properties>property,omitempty"`
+		Skipped    *junitMessage   `xml:"skipped,omitempty"`
+		Failure    *junitMessage   `xml:"failure,omitempty"`
+		Error      *junitMessage   `xml:"error,omitempty"`
+	}
+	junitProperty struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:"value,attr"`
+	}
+	junitMessage struct {
+		Message     string `xml:"message,attr,omitempty"`
+		Type        string `xml:"type,attr,omitempty"`
+		Description string `xml:",chardata"`
+	}
+)
+
+func NewJUnitFileManager() *JUnitFileManager {
+	return &JUnitFileManager{results: map[string]junitResult{}}
+}
+
+func (j *JUnitFileManager) SaveJSONResult(result allure.Result) error {
+	key := result.HistoryID
+	if key == "" {
+		key = result.UUID.String()
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if previous, ok := j.results[key]; ok && result.Stop < previous.stop {
+		return nil
+	}
+	j.results[key] = newJUnitResult(result)
+	return nil
+}
+
+func newJUnitResult(result allure.Result) junitResult {
+	r := junitResult{
+		name:        result.Name,
+		suite:       report.GetLabelValue(result.Labels, string(allure.Suite)),
+		status:      result.Status,
+		start:       result.Start,
+		stop:        result.Stop,
+		message:     result.StatusDetails.Message,
+		trace:       result.StatusDetails.Trace,
+		failedSteps: getFailedStepPath(result.Steps),
+	}
+	for _, label := range result.Labels {
+		if label == nil {
+			continue
+		}
+		r.properties = append(r.properties, junitProperty{Name: label.Name, Value: label.GetValue()})
+	}
+	return r
+}
+
+func (j *JUnitFileManager) SaveAttachment(_ *allure.Attachment) error   { return nil }
+func (j *JUnitFileManager) SaveJSONContainer(_ allure.Container) error  { return nil }
+func (j *JUnitFileManager) SaveCategories(_ []metadata.Category) error  { return nil }
+func (j *JUnitFileManager) SaveEnvironment(_ []metadata.Property) error { return nil }
+func (j *JUnitFileManager) SaveExecutor(_ metadata.Executor) error      { return nil }
+
+// WriteTo writes JUnit XML report. Suites are sorted by name, test cases by start time
+// and name, so the report doesn't depend on the saving order.
+func (j *JUnitFileManager) WriteTo(w io.Writer) (int64, error) {
+	report := j.getReport()
+	cw := &countWriter{w: w}
+	_, err := io.WriteString(cw, xml.Header)
+	if err != nil {
+		return cw.n, errors.Wrap(err, "Cannot write JUnit report")
+	}
+	enc := xml.NewEncoder(cw)
+	enc.Indent("", junitIndent)
+	err = enc.Encode(report)
+	if err != nil {
+		return cw.n, errors.Wrap(err, "Failed marshal JUnit report")
+	}
+	_, err = io.WriteString(cw, "\n")
+	if err != nil {
+		return cw.n, errors.Wrap(err, "Cannot write JUnit report")
+	}
+	return cw.n, nil
+}
+
+// Save writes JUnit XML report to the file. The report is written to a temp file and
+// renamed, so the path never contains a partially written report.
+func (j *JUnitFileManager) Save(path string) error {
+	content := &strings.Builder{}
+	_, err := j.WriteTo(content)
+	if err != nil {
+		return err
+	}
+	err = writeFileAtomic(path, []byte(content.String()))
+	if err != nil {
+		return errors.Wrap(err, "Cannot save JUnit report")
+	}
+	return nil
+}
+
+func (j *JUnitFileManager) getReport() junitTestSuites {
+	j.mu.Lock()
+	results := make([]junitResult, 0, len(j.results))
+	for _, result := range j.results {
+		results = append(results, result)
+	}
+	j.mu.Unlock()
+	sort.SliceStable(results, func(i, k int) bool {
+		if results[i].start != results[k].start {
+			return results[i].start < results[k].start
+		}
+		return results[i].name < results[k].name
+	})
+
+	suitesIdx := map[string]int{}
+	report := junitTestSuites{}
+	for _, result := range results {
+		suiteName := result.suite
+		i, ok := suitesIdx[suiteName]
+		if !ok {
+			i = len(report.TestSuites)
+			suitesIdx[suiteName] = i
+			report.TestSuites = append(report.TestSuites, junitTestSuite{Name: suiteName})
+		}
+		suite := &report.TestSuites[i]
+		testCase := getJUnitTestCase(result, suiteName)
+		suite.TestCases = append(suite.TestCases, testCase)
+		suite.Tests++
+		suite.Time += testCase.Time
+		switch {
+		case testCase.Failure != nil:
+			suite.Failures++
+		case testCase.Error != nil:
+			suite.Errors++
+		case testCase.Skipped != nil:
+			suite.Skipped++
+		}
+	}
+	sort.SliceStable(report.TestSuites, func(i, k int) bool {
+		return report.TestSuites[i].Name < report.TestSuites[k].Name
+	})
+	for _, suite := range report.TestSuites {
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		report.Skipped += suite.Skipped
+		report.Time += suite.Time
+	}
+	return report
+}
+
+func getJUnitTestCase(result junitResult, suiteName string) junitTestCase {
+	testCase := junitTestCase{
+		Name:       result.name,
+		Classname:  suiteName,
+		Status:     string(result.status),
+		Time:       float64(result.stop-result.start) / 1000,
+		Properties: result.properties,
+	}
+	if testCase.Time < 0 {
+		testCase.Time = 0
+	}
+	message := &junitMessage{
+		Message:     result.message,
+		Type:        string(result.status),
+		Description: getJUnitFailureDescription(result),
+	}
+	switch result.status {
+	case allure.Passed:
+	case allure.Failed:
+		testCase.Failure = message
+	case allure.Broken:
+		testCase.Error = message
+	default:
+		testCase.Skipped = &junitMessage{Message: result.message}
+	}
+	return testCase
+}
+
+// getJUnitFailureDescription describes the failure with the failed step (as it's marked
+// in Allure) and the trace.
+func getJUnitFailureDescription(result junitResult) string {
+	lines := []string{}
+	if len(result.failedSteps) != 0 {
+		lines = append(lines, fmt.Sprintf("Failed step: %s", strings.Join(result.failedSteps, junitStepSeparator)))
+	}
+	if result.trace != "" {
+		lines = append(lines, result.trace)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// getFailedStepPath returns names of steps from the top one to the first failed step and
+// its failed children.
+func getFailedStepPath(steps []*allure.Step) []string {
+	for _, step := range steps {
+		if step == nil {
+			continue
+		}
+		path := getFailedStepPath(step.Steps)
+		if len(path) != 0 || step.Status == allure.Failed || step.Status == allure.Broken {
+			return append([]string{step.Name}, path...)
+		}
+	}
+	return nil
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,84 @@
+package filemanager_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+
+	fmngr "github.com/Moon1706/ginkgo2allure/pkg/convert/file_manager"
+	"github.com/google/uuid"
+	"github.com/onsi/ginkgo/v2/reporters"
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJUnitFileManager(t *testing.T) {
+	fm := fmngr.NewJUnitFileManager()
+	results := []allure.Result{{
+		UUID: uuid.New(), HistoryID: "failed", Name: "failed", Status: allure.Failed, Start: 2000, Stop: 3500,
+		Labels:        []*allure.Label{allure.SuiteLabel("E2E"), allure.EpicLabel("payments")},
+		StatusDetails: allure.StatusDetail{Message: "expected true", Trace: "main.go:10"},
+		Steps: []*allure.Step{{Name: "[BeforeEach] setup", Status: allure.Passed}, {
+			Name: "[It] failed", Status: allure.Failed,
+			Steps: []*allure.Step{{Name: "create pod", Status: allure.Failed}},
+		}},
+	}, {
+		UUID: uuid.New(), HistoryID: "failed", Name: "failed", Status: allure.Passed, Start: 1000, Stop: 1500,
+		Labels: []*allure.Label{allure.SuiteLabel("E2E")},
+	}, {
+		UUID: uuid.New(), HistoryID: "passed", Name: "passed", Status: allure.Passed, Start: 1000, Stop: 2000,
+		Labels: []*allure.Label{allure.SuiteLabel("E2E")},
+	}, {
+		UUID: uuid.New(), HistoryID: "broken", Name: "broken", Status: allure.Broken,
+		Labels:        []*allure.Label{allure.SuiteLabel("Backup")},
+		StatusDetails: allure.StatusDetail{Message: "BeforeSuite failed"},
+		Steps: []*allure.Step{{
+			Name: "[It] broken", Status: allure.Passed,
+			Steps: []*allure.Step{{Name: "wait", Status: allure.Broken}},
+		}},
+	}, {
+		UUID: uuid.New(), HistoryID: "skipped", Name: "skipped", Status: allure.Skipped,
+		Labels:        []*allure.Label{allure.SuiteLabel("Backup")},
+		StatusDetails: allure.StatusDetail{Message: "not ready"},
+	}}
+	for _, result := range results {
+		assert.Empty(t, fm.SaveJSONResult(result), "result saved")
+	}
+	assert.Empty(t, fm.SaveAttachment(allure.NewAttachment("log", allure.Text, []byte("log"))), "attachment ignored")
+
+	content := &bytes.Buffer{}
+	_, err := fm.WriteTo(content)
+	assert.Empty(t, err, "JUnit report written")
+	report := reporters.JUnitTestSuites{}
+	assert.Empty(t, xml.Unmarshal(content.Bytes(), &report), "JUnit report decoded by Ginkgo")
+	assert.Equal(t, 4, report.Tests, "retries reported once")
+	assert.Equal(t, 1, report.Failures, "failures")
+	assert.Equal(t, 1, report.Errors, "errors")
+	assert.Len(t, report.TestSuites, 2, "suites by suite label")
+
+	backup, e2e := report.TestSuites[0], report.TestSuites[1]
+	assert.Equal(t, "Backup", backup.Name, "suites sorted")
+	assert.Equal(t, "BeforeSuite failed", backup.TestCases[0].Error.Message, "broken result is error")
+	assert.Equal(t, "Failed step: [It] broken > wait", backup.TestCases[0].Error.Description,
+		"failed nested step")
+	assert.Equal(t, "not ready", backup.TestCases[1].Skipped.Message, "skipped result")
+
+	assert.Equal(t, "E2E", e2e.Name, "suite name")
+	assert.Equal(t, []string{"passed", "failed"}, []string{e2e.TestCases[0].Name, e2e.TestCases[1].Name},
+		"test cases sorted by start")
+	assert.Equal(t, "E2E", e2e.TestCases[1].Classname, "classname is suite")
+	assert.Equal(t, "failed", e2e.TestCases[1].Status, "Allure status")
+	assert.Equal(t, 1.5, e2e.TestCases[1].Time, "time of the latest attempt")
+	assert.Equal(t, "expected true", e2e.TestCases[1].Failure.Message, "failure message")
+	assert.Equal(t, "Failed step: [It] failed > create pod\nmain.go:10", e2e.TestCases[1].Failure.Description,
+		"failed step and trace")
+	assert.Contains(t, content.String(), `<property name="epic" value="payments"></property>`, "labels kept")
+
+	path := filepath.Join(t.TempDir(), "junit.xml")
+	assert.Empty(t, fm.Save(path), "JUnit report saved")
+	saved, err := os.ReadFile(path)
+	assert.Empty(t, err, "JUnit report read")
+	assert.Equal(t, content.String(), string(saved), "same report saved")
+}
@@ -0,0 +1,188 @@
+package filemanager
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/metadata"
+	"github.com/google/uuid"
+	"github.com/ozontech/allure-go/pkg/allure"
+)
+
+type (
+	// MemoryFileManager keeps saved files and objects in memory, e.g. to upload them to
+	// Allure server or to check them in tests. Objects saved several times (e.g. results with
+	// the same UUID) are kept with the latest content.
+	MemoryFileManager struct {
+		files         map[string][]byte
+		results       []allure.Result
+		containers    []allure.Container
+		resultsIdx    map[uuid.UUID]int
+		containersIdx map[uuid.UUID]int
+		categories    []metadata.Category
+		environment   []metadata.Property
+		executor      *metadata.Executor
+		mu            sync.Mutex
+		saver
+	}
+	MemoryFile struct {
+		Name    string
+		Content []byte
+	}
+)
+
+func NewMemoryFileManager() *MemoryFileManager {
+	m := &MemoryFileManager{
+		files:         map[string][]byte{},
+		resultsIdx:    map[uuid.UUID]int{},
+		containersIdx: map[uuid.UUID]int{},
+	}
+	m.saver = saver{createUUIDFile: m.addFile, createFile: m.addFile}
+	return m
+}
+
+func (m *MemoryFileManager) addFile(name string, content []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[name] = content
+	return nil
+}
+
+func (m *MemoryFileManager) SaveJSONResult(result allure.Result) error {
+	err := m.saver.SaveJSONResult(result)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if i, ok := m.resultsIdx[result.UUID]; ok {
+		m.results[i] = result
+		return nil
+	}
+	m.resultsIdx[result.UUID] = len(m.results)
+	m.results = append(m.results, result)
+	return nil
+}
+
+func (m *MemoryFileManager) SaveJSONContainer(container allure.Container) error {
+	err := m.saver.SaveJSONContainer(container)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if i, ok := m.containersIdx[container.UUID]; ok {
+		m.containers[i] = container
+		return nil
+	}
+	m.containersIdx[container.UUID] = len(m.containers)
+	m.containers = append(m.containers, container)
+	return nil
+}
+
+func (m *MemoryFileManager) SaveCategories(categories []metadata.Category) error {
+	err := m.saver.SaveCategories(categories)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.categories = categories
+	return nil
+}
+
+func (m *MemoryFileManager) SaveEnvironment(properties []metadata.Property) error {
+	err := m.saver.SaveEnvironment(properties)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.environment = properties
+	return nil
+}
+
+func (m *MemoryFileManager) SaveExecutor(executor metadata.Executor) error {
+	err := m.saver.SaveExecutor(executor)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.executor = &executor
+	return nil
+}
+
+// RemoveAttachment drops the saved attachment.
+func (m *MemoryFileManager) RemoveAttachment(source string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.files, source)
+	return nil
+}
+
+// GetFiles returns all saved files sorted by name.
+func (m *MemoryFileManager) GetFiles() []MemoryFile {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	files := make([]MemoryFile, 0, len(m.files))
+	for name, content := range m.files {
+		files = append(files, MemoryFile{Name: name, Content: content})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files
+}
+
+// GetFile returns content of the saved file, e.g. attachment by its source.
+func (m *MemoryFileManager) GetFile(name string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	content, ok := m.files[name]
+	return content, ok
+}
+
+// GetResults returns saved results in the order they were saved first.
+func (m *MemoryFileManager) GetResults() []allure.Result {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]allure.Result{}, m.results...)
+}
+
+// GetContainers returns saved containers in the order they were saved first.
+func (m *MemoryFileManager) GetContainers() []allure.Container {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]allure.Container{}, m.containers...)
+}
+
+func (m *MemoryFileManager) GetCategories() []metadata.Category {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.categories
+}
+
+func (m *MemoryFileManager) GetEnvironment() []metadata.Property {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.environment
+}
+
+func (m *MemoryFileManager) GetExecutor() (metadata.Executor, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.executor == nil {
+		return metadata.Executor{}, false
+	}
+	return *m.executor, true
+}
+
+// Cleanup drops everything saved.
+func (m *MemoryFileManager) Cleanup() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files = map[string][]byte{}
+	m.results, m.containers = nil, nil
+	m.resultsIdx, m.containersIdx = map[uuid.UUID]int{}, map[uuid.UUID]int{}
+	m.categories, m.environment, m.executor = nil, nil, nil
+	return nil
+}
@@ -0,0 +1,52 @@
+package filemanager_test
+
+import (
+	"testing"
+
+	fmngr "github.com/Moon1706/ginkgo2allure/pkg/convert/file_manager"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/metadata"
+	"github.com/google/uuid"
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryFileManager(t *testing.T) {
+	fm := fmngr.NewMemoryFileManager()
+	id := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	assert.Empty(t, fm.SaveJSONResult(allure.Result{UUID: id, Name: "first"}), "result saved")
+	assert.Empty(t, fm.SaveJSONResult(allure.Result{UUID: id, Name: "second"}), "result replaced")
+	assert.Empty(t, fm.SaveExecutor(metadata.Executor{Name: "CI"}), "executor saved")
+
+	files := fm.GetFiles()
+	assert.Equal(t, []string{id.String() + "-result.json", metadata.ExecutorFileName},
+		[]string{files[0].Name, files[1].Name}, "files sorted by name")
+	assert.Contains(t, string(files[0].Content), `"name":"second"`, "latest content kept")
+	assert.Equal(t, []allure.Result{{UUID: id, Name: "second"}}, fm.GetResults(), "latest result kept")
+	executor, ok := fm.GetExecutor()
+	assert.True(t, ok, "executor saved")
+	assert.Equal(t, "CI", executor.Name, "executor kept")
+
+	assert.Empty(t, fm.Cleanup(), "files dropped")
+	assert.Empty(t, fm.GetFiles(), "no files after cleanup")
+}
+
+func TestMemoryFileManagerObjects(t *testing.T) {
+	fm := fmngr.NewMemoryFileManager()
+	attachment := allure.NewAttachment("test", allure.Text, []byte("test"))
+	containers := []allure.Container{{UUID: uuid.New()}, {UUID: uuid.New()}}
+	for _, container := range containers {
+		assert.Empty(t, fm.SaveJSONContainer(container), "container saved")
+	}
+	assert.Empty(t, fm.SaveAttachment(attachment), "attachment saved")
+	assert.Empty(t, fm.SaveCategories(metadata.GetDefaultCategories()), "categories saved")
+	assert.Empty(t, fm.SaveEnvironment([]metadata.Property{{Key: "a", Value: "b"}}), "environment saved")
+
+	assert.Equal(t, containers, fm.GetContainers(), "containers in saving order")
+	content, ok := fm.GetFile(attachment.Source)
+	assert.True(t, ok, "attachment found")
+	assert.Equal(t, "test", string(content), "attachment content")
+	assert.Equal(t, metadata.GetDefaultCategories(), fm.GetCategories(), "categories kept")
+	assert.Equal(t, []metadata.Property{{Key: "a", Value: "b"}}, fm.GetEnvironment(), "environment kept")
+	_, ok = fm.GetExecutor()
+	assert.False(t, ok, "executor not saved")
+}
@@ -0,0 +1,80 @@
+package filemanager
+
+import (
+	stderrors "errors"
+
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/metadata"
+	"github.com/ozontech/allure-go/pkg/allure"
+)
+
+type multiFileManager struct {
+	fms []FileManager
+}
+
+// NewMultiFileManager creates file manager which saves everything with all file managers.
+// Saving continues after errors, errors of all file managers are joined.
+func NewMultiFileManager(fms ...FileManager) FileManager {
+	return &multiFileManager{fms: fms}
+}
+
+func (m *multiFileManager) each(save func(fm FileManager) error) error {
+	errs := []error{}
+	for _, fm := range m.fms {
+		errs = append(errs, save(fm))
+	}
+	return stderrors.Join(errs...)
+}
+
+func (m *multiFileManager) SaveJSONResult(result allure.Result) error {
+	return m.each(func(fm FileManager) error { return fm.SaveJSONResult(result) })
+}
+
+func (m *multiFileManager) SaveAttachment(attachment *allure.Attachment) error {
+	return m.each(func(fm FileManager) error { return fm.SaveAttachment(attachment) })
+}
+
+func (m *multiFileManager) SaveJSONContainer(container allure.Container) error {
+	return m.each(func(fm FileManager) error { return fm.SaveJSONContainer(container) })
+}
+
+func (m *multiFileManager) SaveCategories(categories []metadata.Category) error {
+	return m.each(func(fm FileManager) error { return fm.SaveCategories(categories) })
+}
+
+func (m *multiFileManager) SaveEnvironment(properties []metadata.Property) error {
+	return m.each(func(fm FileManager) error { return fm.SaveEnvironment(properties) })
+}
+
+func (m *multiFileManager) SaveExecutor(executor metadata.Executor) error {
+	return m.each(func(fm FileManager) error { return fm.SaveExecutor(executor) })
+}
+
+// RemoveAttachment removes the attachment with all file managers which implement AttachmentRemover.
+func (m *multiFileManager) RemoveAttachment(source string) error {
+	return m.each(func(fm FileManager) error {
+		if remover, ok := fm.(AttachmentRemover); ok {
+			return remover.RemoveAttachment(source)
+		}
+		return nil
+	})
+}
+
+// Cleanup cleans up all file managers which implement Cleaner.
+func (m *multiFileManager) Cleanup() error {
+	return m.each(func(fm FileManager) error {
+		if cleaner, ok := fm.(Cleaner); ok {
+			return cleaner.Cleanup()
+		}
+		return nil
+	})
+}
+
+// SaveManifest saves manifests of all file managers which implement ManifestSaver.
+func (m *multiFileManager) SaveManifest() error {
+	return m.each(func(fm FileManager) error {
+		if manifestSaver, ok := fm.(ManifestSaver); ok {
+			return manifestSaver.SaveManifest()
+		}
+		return nil
+	})
+}
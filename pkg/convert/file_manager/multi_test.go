@@ -0,0 +1,37 @@
+package filemanager_test
+
+import (
+	"testing"
+
+	fmngr "github.com/Moon1706/ginkgo2allure/pkg/convert/file_manager"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/metadata"
+	"github.com/google/uuid"
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiFileManager(t *testing.T) {
+	first, second := fmngr.NewMemoryFileManager(), fmngr.NewMemoryFileManager()
+	fm := fmngr.NewMultiFileManager(first, second)
+	result := allure.Result{UUID: uuid.New()}
+	assert.Empty(t, fm.SaveJSONResult(result), "result saved")
+	assert.Empty(t, fm.SaveAttachment(allure.NewAttachment("test", allure.Text, []byte("test"))),
+		"attachment saved")
+	assert.Empty(t, fm.SaveJSONContainer(allure.Container{UUID: uuid.New()}), "container saved")
+	assert.Empty(t, fm.SaveCategories(nil), "categories saved")
+	assert.Empty(t, fm.SaveEnvironment(nil), "environment saved")
+	assert.Empty(t, fm.SaveExecutor(metadata.Executor{}), "executor saved")
+	for _, memory := range []*fmngr.MemoryFileManager{first, second} {
+		assert.Equal(t, []allure.Result{result}, memory.GetResults(), "result saved by each file manager")
+		assert.Len(t, memory.GetFiles(), 6, "all files saved by each file manager")
+	}
+
+	assert.Empty(t, fm.(fmngr.Cleaner).Cleanup(), "file managers cleaned up")
+	assert.Empty(t, first.GetFiles(), "first file manager cleaned up")
+	assert.Empty(t, second.GetFiles(), "second file manager cleaned up")
+	assert.Empty(t, fm.(fmngr.ManifestSaver).SaveManifest(), "nothing to save")
+
+	failed := fmngr.NewMultiFileManager(newFileManager(t, "/nonexistent/folder"), first)
+	assert.Error(t, failed.SaveJSONResult(result), "error of one file manager returned")
+	assert.Equal(t, []allure.Result{result}, first.GetResults(), "saving continues after error")
+}
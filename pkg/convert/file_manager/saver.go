@@ -0,0 +1,84 @@
+package filemanager
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/metadata"
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/pkg/errors"
+)
+
+// saver converts Allure objects to files and saves them with functions of the file manager.
+type saver struct {
+	createUUIDFile func(name string, content []byte) error
+	createFile     func(name string, content []byte) error
+}
+
+func (s saver) SaveJSONResult(result allure.Result) error {
+	bResult, err := json.Marshal(result)
+	if err != nil {
+		return errors.Wrap(err, "Failed marshal Result")
+	}
+
+	err = s.createUUIDFile(fmt.Sprintf("%s-result.json", result.UUID), bResult)
+	if err != nil {
+		return errors.Wrap(err, "Cannot save Result")
+	}
+	return nil
+}
+
+func (s saver) SaveAttachment(attachment *allure.Attachment) error {
+	err := s.createUUIDFile(attachment.Source, attachment.GetContent())
+	if err != nil {
+		return errors.Wrap(err, "Cannot save Attachment")
+	}
+	return nil
+}
+
+func (s saver) SaveJSONContainer(container allure.Container) error {
+	bContainer, err := json.Marshal(container)
+	if err != nil {
+		return errors.Wrap(err, "Failed marshal Container")
+	}
+
+	err = s.createUUIDFile(fmt.Sprintf("%s-container.json", container.UUID), bContainer)
+	if err != nil {
+		return errors.Wrap(err, "Cannot save Container")
+	}
+	return nil
+}
+
+func (s saver) SaveCategories(categories []metadata.Category) error {
+	bCategories, err := json.Marshal(categories)
+	if err != nil {
+		return errors.Wrap(err, "Failed marshal Categories")
+	}
+
+	err = s.createFile(metadata.CategoriesFileName, bCategories)
+	if err != nil {
+		return errors.Wrap(err, "Cannot save Categories")
+	}
+	return nil
+}
+
+func (s saver) SaveEnvironment(properties []metadata.Property) error {
+	err := s.createFile(metadata.EnvironmentFileName, metadata.FormatProperties(properties))
+	if err != nil {
+		return errors.Wrap(err, "Cannot save Environment")
+	}
+	return nil
+}
+
+func (s saver) SaveExecutor(executor metadata.Executor) error {
+	bExecutor, err := json.Marshal(executor)
+	if err != nil {
+		return errors.Wrap(err, "Failed marshal Executor")
+	}
+
+	err = s.createFile(metadata.ExecutorFileName, bExecutor)
+	if err != nil {
+		return errors.Wrap(err, "Cannot save Executor")
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package convert
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	fmngr "github.com/Moon1706/ginkgo2allure/pkg/convert/file_manager"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/parser"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/report"
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/pkg/errors"
+)
+
+type (
+	// ConvertedSpecs is the set of keys (see GetSpecKey) of specs converted before.
+	ConvertedSpecs map[string]bool
+
+	// State is the progress of incremental conversion: converted specs and names of files
+	// saved for them, so the files are overwritten when specs are converted again.
+	State struct {
+		Specs ConvertedSpecs
+		Files []string
+	}
+	savedState struct {
+		Specs []string `json:"specs"`
+		Files []string `json:"files"`
+	}
+
+	// IncrementalConverter converts only specs which were not converted before, so the same
+	// report can be converted again while it grows.
+	IncrementalConverter struct {
+		fm             fmngr.FileManager
+		parserCreation parser.CreationFunc
+		config         parser.Config
+		converted      ConvertedSpecs
+		summary        *Summary
+	}
+)
+
+func NewIncrementalConverter(fm fmngr.FileManager, parserCreation parser.CreationFunc, config parser.Config,
+	converted ConvertedSpecs) *IncrementalConverter {
+	if converted == nil {
+		converted = ConvertedSpecs{}
+	}
+	return &IncrementalConverter{
+		fm:             fm,
+		parserCreation: parserCreation,
+		config:         config,
+		converted:      converted,
+		summary:        NewSummary(),
+	}
+}
+
+// GetSpecKey identifies spec of the suite run in the input, specs of the next run of the same
+// suite get other keys.
+func GetSpecKey(inputName string, ginkgoReport types.Report, specReport types.SpecReport) string {
+	hash := sha256.Sum256([]byte(strings.Join([]string{
+		inputName,
+		ginkgoReport.SuiteDescription,
+		ginkgoReport.StartTime.String(),
+		specReport.LeafNodeType.String(),
+		specReport.FullText(),
+		specReport.LeafNodeLocation.String(),
+	}, "\x00")))
+	return hex.EncodeToString(hash[:])
+}
+
+// Convert converts and saves new specs of the reports and returns the number of saved specs.
+// Suite level nodes are converted with each part of the suite, so every part has its fixtures.
+// Specs without mandatory labels are rejected and counted in the summary, they aren't converted again.
+func (c *IncrementalConverter) Convert(ctx context.Context, ginkgoReports []types.Report,
+	inputName string) (int, []error) {
+	count := 0
+	for _, ginkgoReport := range ginkgoReports {
+		newReport, keys := c.getNewSpecs(inputName, ginkgoReport)
+		if len(keys) == 0 {
+			continue
+		}
+		converted, errs := c.convertReport(ctx, newReport, keys)
+		count += converted
+		if len(errs) != 0 {
+			return count, errs
+		}
+	}
+	return count, nil
+}
+
+// GetSummary returns counts of converted results and specs which weren't converted.
+func (c *IncrementalConverter) GetSummary() *Summary {
+	return c.summary
+}
+
+// convertReport saves specs one by one and records keys of saved specs, so specs saved before
+// an error aren't converted again.
+func (c *IncrementalConverter) convertReport(ctx context.Context, ginkgoReport types.Report,
+	keys map[int]string) (int, []error) {
+	count := 0
+	suite := newSuiteConverter(ginkgoReport, c.parserCreation, c.config)
+	suite.summary = c.summary
+	errs := []error{}
+	for i, specReport := range ginkgoReport.SpecReports {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		key, isNew := keys[i]
+		if isNew && specReport.LeafNodeType != types.NodeTypeIt {
+			c.summary.AddNotIt()
+		}
+		results, containers, err := suite.convertSpec(specReport)
+		if errors.Is(err, report.ErrMissingLabel) {
+			c.summary.AddRejected(err)
+		} else if err != nil {
+			errs = append(errs, err)
+			break
+		}
+		specErrs := PrintAllureReportsContext(ctx, results, c.fm)
+		specErrs = append(specErrs, PrintAllureContainers(containers, c.fm)...)
+		if len(specErrs) != 0 {
+			errs = append(errs, specErrs...)
+			break
+		}
+		if isNew {
+			c.converted[key] = true
+		}
+		if len(results) != 0 {
+			c.summary.AddResult(results[len(results)-1])
+			if isNew {
+				count++
+			}
+		}
+	}
+	if len(errs) != 0 {
+		return count, errs
+	}
+	if container, ok := suite.getContainer(ginkgoReport); ok {
+		errs = append(errs, PrintAllureContainers([]allure.Container{container}, c.fm)...)
+	}
+	return count, errs
+}
+
+// getNewSpecs returns the report with suite level nodes and new specs, keys of new specs are
+// indexed by their position in the returned report.
+func (c *IncrementalConverter) getNewSpecs(inputName string, ginkgoReport types.Report) (types.Report, map[int]string) {
+	keys := map[int]string{}
+	specReports := types.SpecReports{}
+	for _, specReport := range ginkgoReport.SpecReports {
+		if specReport.LeafNodeType.Is(types.NodeTypesForSuiteLevelNodes) {
+			specReports = append(specReports, specReport)
+			continue
+		}
+		key := GetSpecKey(inputName, ginkgoReport, specReport)
+		if c.converted[key] {
+			continue
+		}
+		keys[len(specReports)] = key
+		specReports = append(specReports, specReport)
+	}
+	ginkgoReport.SpecReports = specReports
+	return ginkgoReport, keys
+}
+
+// LoadState loads the state saved before, missing file means nothing was converted.
+func LoadState(path string) (State, error) {
+	state := State{Specs: ConvertedSpecs{}}
+	bState, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return State{}, errors.Wrap(err, "Cannot read state")
+	}
+	saved := savedState{}
+	err = json.Unmarshal(bState, &saved)
+	if err != nil {
+		return State{}, errors.Wrap(err, "Failed unmarshal state")
+	}
+	for _, key := range saved.Specs {
+		state.Specs[key] = true
+	}
+	state.Files = saved.Files
+	return state, nil
+}
+
+// Save saves the state, the file is replaced atomically.
+func (s State) Save(path string) error {
+	saved := savedState{Specs: make([]string, 0, len(s.Specs)), Files: append([]string{}, s.Files...)}
+	for key := range s.Specs {
+		saved.Specs = append(saved.Specs, key)
+	}
+	sort.Strings(saved.Specs)
+	sort.Strings(saved.Files)
+	bState, err := json.Marshal(saved)
+	if err != nil {
+		return errors.Wrap(err, "Failed marshal state")
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return errors.Wrap(err, "Cannot save state")
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(bState)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		return errors.Wrap(err, "Cannot save state")
+	}
+	return nil
+}
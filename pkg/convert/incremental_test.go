@@ -0,0 +1,130 @@
+package convert_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Moon1706/ginkgo2allure/pkg/convert"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/parser"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/report"
+	"github.com/google/uuid"
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/stretchr/testify/assert"
+)
+
+type failResultsFileManager struct {
+	recordFileManager
+	fail bool
+}
+
+func (m *failResultsFileManager) SaveJSONResult(result allure.Result) error {
+	if m.fail {
+		return errTest
+	}
+	return m.recordFileManager.SaveJSONResult(result)
+}
+
+func TestIncrementalConverter(t *testing.T) {
+	ginkgoReport := types.Report{
+		SuiteDescription: "Soak",
+		StartTime:        time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		SpecReports: types.SpecReports{
+			{LeafNodeType: types.NodeTypeBeforeSuite, State: types.SpecStatePassed},
+			{LeafNodeType: types.NodeTypeIt, LeafNodeText: "first"},
+			{LeafNodeType: types.NodeTypeIt, LeafNodeText: "second"},
+		},
+	}
+	fm := &recordFileManager{}
+	converter := convert.NewIncrementalConverter(fm, namedParser, parser.Config{}, nil)
+	count, errs := converter.Convert(context.Background(), []types.Report{ginkgoReport}, "soak.json")
+	assert.Empty(t, errs, "no conversion errors")
+	assert.Equal(t, 2, count, "all specs converted")
+	assert.Len(t, fm.Containers, 1, "suite fixtures saved")
+
+	ginkgoReport.SpecReports = append(ginkgoReport.SpecReports,
+		types.SpecReport{LeafNodeType: types.NodeTypeIt, LeafNodeText: "third"})
+	count, errs = converter.Convert(context.Background(), []types.Report{ginkgoReport}, "soak.json")
+	assert.Empty(t, errs, "no conversion errors")
+	assert.Equal(t, 1, count, "only new spec converted")
+	assert.Equal(t, "third", fm.Results[2].Name, "new spec saved")
+	assert.Len(t, fm.Containers, 2, "suite fixtures saved with new spec")
+
+	count, _ = converter.Convert(context.Background(), []types.Report{ginkgoReport}, "soak.json")
+	assert.Equal(t, 0, count, "nothing converted twice")
+	count, _ = converter.Convert(context.Background(), []types.Report{ginkgoReport}, "other.json")
+	assert.Equal(t, 3, count, "specs of other input converted")
+
+	ginkgoReport.StartTime = ginkgoReport.StartTime.Add(time.Hour)
+	count, _ = converter.Convert(context.Background(), []types.Report{ginkgoReport}, "soak.json")
+	assert.Equal(t, 3, count, "specs of the next run converted")
+}
+
+func TestIncrementalConverterRejectSpecs(t *testing.T) {
+	ginkgoReport := types.Report{
+		SuiteDescription: "Soak",
+		SpecReports: types.SpecReports{
+			{LeafNodeType: types.NodeTypeIt, LeafNodeText: "unlabeled", State: types.SpecStatePassed},
+			{LeafNodeType: types.NodeTypeIt, LeafNodeText: "labeled", State: types.SpecStateFailed,
+				LeafNodeLabels: []string{"id=" + uuid.New().String()}},
+		},
+	}
+	fm := &recordFileManager{}
+	converter := convert.NewIncrementalConverter(fm, parser.NewDefaultParser, parser.Config{}, nil)
+	count, errs := converter.Convert(context.Background(), []types.Report{ginkgoReport}, "soak.json")
+	assert.Empty(t, errs, "spec without labels doesn't stop conversion")
+	assert.Equal(t, 1, count, "only saved spec counted")
+	assert.Len(t, fm.Results, 1, "labeled spec converted")
+	assert.Equal(t, "labeled", fm.Results[0].Name, "labeled spec saved")
+	summary := converter.GetSummary()
+	assert.Len(t, summary.GetRejected(), 1, "spec without labels rejected")
+	assert.ErrorIs(t, summary.GetRejected()[0], report.ErrMissingLabel, "missing label error")
+	assert.Equal(t, convert.StatusCounts{allure.Failed: 1}, summary.GetStatuses(), "converted results counted")
+
+	count, errs = converter.Convert(context.Background(), []types.Report{ginkgoReport}, "soak.json")
+	assert.Empty(t, errs, "no conversion errors")
+	assert.Equal(t, 0, count, "nothing converted twice")
+	assert.Len(t, fm.Results, 1, "nothing saved twice")
+	assert.Len(t, summary.GetRejected(), 1, "spec isn't rejected twice")
+}
+
+func TestIncrementalConverterSaveError(t *testing.T) {
+	ginkgoReport := types.Report{
+		SuiteDescription: "Soak",
+		SpecReports: types.SpecReports{
+			{LeafNodeType: types.NodeTypeBeforeSuite, State: types.SpecStatePassed},
+			{LeafNodeType: types.NodeTypeIt, LeafNodeText: "first"},
+		},
+	}
+	fm := &failResultsFileManager{fail: true}
+	converter := convert.NewIncrementalConverter(fm, namedParser, parser.Config{}, nil)
+	count, errs := converter.Convert(context.Background(), []types.Report{ginkgoReport}, "soak.json")
+	assert.Equal(t, []error{errTest}, errs, "saving error returned")
+	assert.Equal(t, 0, count, "nothing saved")
+	assert.Empty(t, fm.Containers, "suite container not saved without specs")
+
+	fm.fail = false
+	count, errs = converter.Convert(context.Background(), []types.Report{ginkgoReport}, "soak.json")
+	assert.Empty(t, errs, "no conversion errors")
+	assert.Equal(t, 1, count, "spec converted again")
+	assert.Len(t, fm.Containers, 1, "suite container saved")
+}
+
+func TestStateSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	state, err := convert.LoadState(path)
+	assert.Empty(t, err, "missing state is empty")
+	assert.Empty(t, state.Specs, "nothing converted")
+	assert.Empty(t, state.Files, "nothing saved")
+
+	state.Specs["first"] = true
+	state.Specs["second"] = true
+	state.Files = []string{"second-result.json", "first-result.json"}
+	assert.Empty(t, state.Save(path), "state saved")
+	loaded, err := convert.LoadState(path)
+	assert.Empty(t, err, "state loaded")
+	assert.Equal(t, state.Specs, loaded.Specs, "the same specs loaded")
+	assert.Equal(t, []string{"first-result.json", "second-result.json"}, loaded.Files, "the same files loaded")
+}
@@ -0,0 +1,31 @@
+package input
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	FormatJSON  = "json"
+	FormatJUnit = "junit"
+)
+
+// DecodeReports decodes Ginkgo reports of the format (Ginkgo JSON or JUnit XML report).
+func DecodeReports(r io.Reader, format string) ([]types.Report, error) {
+	switch format {
+	case FormatJSON, "":
+		var ginkgoReports []types.Report
+		err := json.NewDecoder(r).Decode(&ginkgoReports)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed unmarshal Ginkgo report")
+		}
+		return ginkgoReports, nil
+	case FormatJUnit:
+		return DecodeJUnitReports(r)
+	}
+	return nil, fmt.Errorf("unknown input format %s, use %s or %s", format, FormatJSON, FormatJUnit)
+}
@@ -0,0 +1,206 @@
+package input
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo/v2/reporters"
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	junitTimestampLayout  = "2006-01-02T15:04:05"
+	junitSkippedMessage   = "skipped"
+	junitSkippedSeparator = " - "
+	junitStackTraceHeader = "Full Stack Trace\n"
+	junitStackTraceIndent = "  "
+)
+
+var (
+	// junitNameRegexp matches test case name: [NodeType] text [label1, label2].
+	junitNameRegexp = regexp.MustCompile(`^\[([^\]]+)\](?: (.*?))?(?: \[([^\[\]]*)\])?$`)
+	// junitLocationRegexp matches failure location in the failure description.
+	junitLocationRegexp = regexp.MustCompile(`In \[([^\]]+)\] at: (\S+):(\d+)`)
+)
+
+// DecodeJUnitReports decodes Ginkgo JUnit XML report. JUnit report doesn't contain
+// spec events and container hierarchy, so specs don't have steps and the whole spec text
+// is the leaf node text. Timeline of the spec from system-err is kept as GinkgoWriter output.
+func DecodeJUnitReports(r io.Reader) ([]types.Report, error) {
+	junitReport := reporters.JUnitTestSuites{}
+	err := xml.NewDecoder(r).Decode(&junitReport)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed unmarshal JUnit report")
+	}
+	ginkgoReports := make([]types.Report, 0, len(junitReport.TestSuites))
+	for _, suite := range junitReport.TestSuites {
+		ginkgoReport, err := getJUnitSuiteReport(suite)
+		if err != nil {
+			return nil, err
+		}
+		ginkgoReports = append(ginkgoReports, ginkgoReport)
+	}
+	return ginkgoReports, nil
+}
+
+func getJUnitSuiteReport(suite reporters.JUnitTestSuite) (types.Report, error) {
+	properties := suite.Properties
+	ginkgoReport := types.Report{
+		SuitePath:                  suite.Package,
+		SuiteDescription:           suite.Name,
+		SuiteSucceeded:             properties.WithName("SuiteSucceeded") == "true",
+		SuiteHasProgrammaticFocus:  properties.WithName("SuiteHasProgrammaticFocus") == "true",
+		SpecialSuiteFailureReasons: splitNotEmpty(properties.WithName("SpecialSuiteFailureReason"), ","),
+		SuiteLabels: splitNotEmpty(strings.TrimSuffix(strings.TrimPrefix(
+			properties.WithName("SuiteLabels"), "["), "]"), ","),
+		RunTime: getJUnitDuration(suite.Time),
+	}
+	ginkgoReport.SuiteConfig.RandomSeed, _ = strconv.ParseInt(properties.WithName("RandomSeed"), 10, 64)
+	ginkgoReport.SuiteConfig.RandomizeAllSpecs = properties.WithName("RandomizeAllSpecs") == "true"
+	ginkgoReport.SuiteConfig.LabelFilter = properties.WithName("LabelFilter")
+	ginkgoReport.SuiteConfig.FocusStrings = splitNotEmpty(properties.WithName("FocusStrings"), ",")
+	ginkgoReport.SuiteConfig.SkipStrings = splitNotEmpty(properties.WithName("SkipStrings"), ",")
+	ginkgoReport.SuiteConfig.FlakeAttempts, _ = strconv.Atoi(properties.WithName("FlakeAttempts"))
+	ginkgoReport.SuiteConfig.ParallelTotal, _ = strconv.Atoi(properties.WithName("ParallelTotal"))
+	if suite.Timestamp != "" {
+		startTime, err := time.Parse(junitTimestampLayout, suite.Timestamp)
+		if err != nil {
+			return ginkgoReport, errors.Wrap(err, "Failed parse JUnit suite timestamp")
+		}
+		ginkgoReport.StartTime = startTime
+		ginkgoReport.EndTime = startTime.Add(ginkgoReport.RunTime)
+	}
+
+	// JUnit report doesn't contain start time of specs, specs are supposed to run one by one.
+	startTime := ginkgoReport.StartTime
+	for _, testCase := range suite.TestCases {
+		specReport, err := getJUnitSpecReport(testCase, startTime)
+		if err != nil {
+			return ginkgoReport, err
+		}
+		startTime = specReport.EndTime
+		ginkgoReport.SpecReports = append(ginkgoReport.SpecReports, specReport)
+	}
+	return ginkgoReport, nil
+}
+
+func getJUnitSpecReport(testCase reporters.JUnitTestCase, startTime time.Time) (types.SpecReport, error) {
+	specReport := types.SpecReport{
+		LeafNodeType:               types.NodeTypeIt,
+		LeafNodeText:               testCase.Name,
+		RunTime:                    getJUnitDuration(testCase.Time),
+		StartTime:                  startTime,
+		CapturedStdOutErr:          testCase.SystemOut,
+		CapturedGinkgoWriterOutput: testCase.SystemErr,
+		NumAttempts:                1,
+	}
+	specReport.EndTime = startTime.Add(specReport.RunTime)
+	if matches := junitNameRegexp.FindStringSubmatch(testCase.Name); matches != nil {
+		var nodeType types.NodeType
+		err := unmarshalJUnitEnum(matches[1], &nodeType)
+		if err != nil {
+			return specReport, err
+		}
+		if nodeType != types.NodeTypeInvalid {
+			specReport.LeafNodeType = nodeType
+			specReport.LeafNodeText = matches[2]
+			specReport.LeafNodeLabels = splitNotEmpty(matches[3], ", ")
+		}
+	}
+	err := unmarshalJUnitEnum(testCase.Status, &specReport.State)
+	if err != nil {
+		return specReport, err
+	}
+	if specReport.State == types.SpecStateInvalid {
+		if testCase.Status != "" {
+			return specReport, fmt.Errorf("unknown state %s of %s", testCase.Status, testCase.Name)
+		}
+		specReport.State = getJUnitState(testCase)
+	}
+
+	switch {
+	case testCase.Failure != nil:
+		specReport.Failure = getJUnitFailure(testCase.Failure.Message, testCase.Failure.Description)
+	case testCase.Error != nil:
+		specReport.Failure = getJUnitFailure(testCase.Error.Message, testCase.Error.Description)
+		if specReport.State == types.SpecStatePanicked {
+			specReport.Failure.Message, specReport.Failure.ForwardedPanic = "", testCase.Error.Message
+		}
+	case testCase.Skipped != nil && specReport.State == types.SpecStateSkipped:
+		specReport.Failure.Message = strings.TrimPrefix(strings.TrimPrefix(testCase.Skipped.Message,
+			junitSkippedMessage), junitSkippedSeparator)
+	}
+	return specReport, nil
+}
+
+// getJUnitState gets state of test case without status attribute (not Ginkgo JUnit report).
+func getJUnitState(testCase reporters.JUnitTestCase) types.SpecState {
+	switch {
+	case testCase.Failure != nil:
+		return types.SpecStateFailed
+	case testCase.Error != nil:
+		return types.SpecStatePanicked
+	case testCase.Skipped != nil:
+		return types.SpecStateSkipped
+	}
+	return types.SpecStatePassed
+}
+
+func getJUnitFailure(message, description string) types.Failure {
+	failure := types.Failure{Message: message}
+	failure.Location.FullStackTrace = getJUnitStackTrace(description)
+	if matches := junitLocationRegexp.FindStringSubmatch(description); matches != nil {
+		_ = unmarshalJUnitEnum(matches[1], &failure.FailureNodeType)
+		failure.Location.FileName = matches[2]
+		failure.Location.LineNumber, _ = strconv.Atoi(matches[3])
+	}
+	return failure
+}
+
+// getJUnitStackTrace gets full stack trace from the failure description. Ginkgo adds it
+// to the description only for panics or with --trace.
+func getJUnitStackTrace(description string) string {
+	_, trace, ok := strings.Cut(description, junitStackTraceHeader)
+	if !ok {
+		return ""
+	}
+	lines := []string{}
+	for _, line := range strings.Split(trace, "\n") {
+		if !strings.HasPrefix(line, junitStackTraceIndent) {
+			break
+		}
+		lines = append(lines, strings.TrimPrefix(line, junitStackTraceIndent))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// unmarshalJUnitEnum parses Ginkgo enum (node type, spec state) from its string representation.
+func unmarshalJUnitEnum(value string, enum json.Unmarshaler) error {
+	bValue, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	err = enum.UnmarshalJSON(bValue)
+	if err != nil {
+		return errors.Wrapf(err, "Failed parse %s", value)
+	}
+	return nil
+}
+
+func getJUnitDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func splitNotEmpty(value, separator string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, separator)
+}
@@ -0,0 +1,135 @@
+package input_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/input"
+	"github.com/onsi/ginkgo/v2/reporters"
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeJUnitReports(t *testing.T) {
+	startTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	location := types.CodeLocation{FileName: "/src/suite_test.go", LineNumber: 42}
+	stackTrace := "suite.glob..func1()\n\t/src/suite_test.go:42 +0x1d\nmain.main()\n\t/src/main.go:10 +0x2a"
+	ginkgoReport := types.Report{
+		SuitePath:        "/src",
+		SuiteDescription: "Suite",
+		SuiteSucceeded:   false,
+		SuiteLabels:      []string{"e2e", "slow"},
+		StartTime:        startTime,
+		RunTime:          3 * time.Second,
+		SpecReports: types.SpecReports{{
+			LeafNodeType: types.NodeTypeBeforeSuite,
+			State:        types.SpecStatePassed,
+			RunTime:      time.Second,
+		}, {
+			ContainerHierarchyTexts: []string{"Pods"},
+			LeafNodeType:            types.NodeTypeIt,
+			LeafNodeText:            "start",
+			LeafNodeLabels:          []string{"id=1", "epic=k8s"},
+			State:                   types.SpecStatePassed,
+			RunTime:                 time.Second,
+			CapturedStdOutErr:       "stdout",
+		}, {
+			LeafNodeType:   types.NodeTypeIt,
+			LeafNodeText:   "fail",
+			LeafNodeLabels: []string{"id=2"},
+			State:          types.SpecStateFailed,
+			RunTime:        time.Second,
+			Failure: types.Failure{
+				Message:         "expected true",
+				Location:        location,
+				FailureNodeType: types.NodeTypeJustBeforeEach,
+			},
+		}, {
+			LeafNodeType: types.NodeTypeIt,
+			LeafNodeText: "panic",
+			State:        types.SpecStatePanicked,
+			Failure: types.Failure{
+				ForwardedPanic: "nil pointer",
+				Location: types.CodeLocation{FileName: location.FileName, LineNumber: location.LineNumber,
+					FullStackTrace: stackTrace},
+				FailureNodeType: types.NodeTypeIt,
+			},
+		}, {
+			LeafNodeType: types.NodeTypeIt,
+			LeafNodeText: "skip",
+			State:        types.SpecStateSkipped,
+			Failure:      types.Failure{Message: "not ready"},
+		}, {
+			LeafNodeType: types.NodeTypeCleanupAfterSuite,
+			State:        types.SpecStatePassed,
+		}},
+	}
+	path := filepath.Join(t.TempDir(), "junit.xml")
+	assert.Empty(t, reporters.GenerateJUnitReport(ginkgoReport, path), "JUnit report generated")
+	file, err := os.Open(path)
+	assert.Empty(t, err, "JUnit report opened")
+	defer file.Close()
+
+	reports, err := input.DecodeReports(file, input.FormatJUnit)
+	assert.Empty(t, err, "JUnit report decoded")
+	assert.Len(t, reports, 1, "one suite")
+	report := reports[0]
+	assert.Equal(t, "Suite", report.SuiteDescription, "suite description")
+	assert.Equal(t, "/src", report.SuitePath, "suite path")
+	assert.Equal(t, []string{"e2e", "slow"}, report.SuiteLabels, "suite labels")
+	assert.False(t, report.SuiteSucceeded, "suite failed")
+	assert.Equal(t, startTime, report.StartTime, "suite start time")
+	assert.Equal(t, startTime.Add(3*time.Second), report.EndTime, "suite end time")
+	assert.Len(t, report.SpecReports, 6, "all specs")
+
+	specs := report.SpecReports
+	assert.Equal(t, types.NodeTypeBeforeSuite, specs[0].LeafNodeType, "suite node type")
+	assert.Equal(t, "", specs[0].LeafNodeText, "suite node text")
+
+	assert.Equal(t, types.NodeTypeIt, specs[1].LeafNodeType, "It node type")
+	assert.Equal(t, "Pods start", specs[1].LeafNodeText, "full text of the spec")
+	assert.Equal(t, []string{"id=1", "epic=k8s"}, specs[1].LeafNodeLabels, "spec labels")
+	assert.Equal(t, types.SpecStatePassed, specs[1].State, "spec passed")
+	assert.Equal(t, "stdout", specs[1].CapturedStdOutErr, "captured output")
+	assert.Equal(t, startTime.Add(time.Second), specs[1].StartTime, "specs run one by one")
+	assert.Equal(t, startTime.Add(2*time.Second), specs[1].EndTime, "spec end time")
+
+	assert.Equal(t, types.SpecStateFailed, specs[2].State, "spec failed")
+	assert.Equal(t, "expected true", specs[2].Failure.Message, "failure message")
+	assert.Equal(t, types.NodeTypeJustBeforeEach, specs[2].Failure.FailureNodeType, "failure node type")
+	assert.Equal(t, location.FileName, specs[2].Failure.Location.FileName, "failure file")
+	assert.Equal(t, location.LineNumber, specs[2].Failure.Location.LineNumber, "failure line")
+	assert.Empty(t, specs[2].Failure.Location.FullStackTrace, "no stack trace in the description")
+
+	assert.Equal(t, types.SpecStatePanicked, specs[3].State, "spec panicked")
+	assert.Equal(t, "nil pointer", specs[3].Failure.ForwardedPanic, "panic")
+	assert.Equal(t, stackTrace, specs[3].Failure.Location.FullStackTrace, "stack trace of the panic")
+
+	assert.Equal(t, types.SpecStateSkipped, specs[4].State, "spec skipped")
+	assert.Equal(t, "not ready", specs[4].Failure.Message, "skip message")
+
+	assert.Equal(t, types.NodeTypeCleanupAfterSuite, specs[5].LeafNodeType, "node type with spaces")
+}
+
+func TestDecodeReportsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		format  string
+	}{
+		{"unknown format", "[]", "yaml"},
+		{"invalid JSON", "<testsuites/>", input.FormatJSON},
+		{"invalid XML", "[]", input.FormatJUnit},
+		{"invalid state", `<testsuites><testsuite><testcase name="[It] a" status="1"/></testsuite></testsuites>`,
+			input.FormatJUnit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := input.DecodeReports(strings.NewReader(tt.content), tt.format)
+			assert.Error(t, err, "report isn't decoded")
+		})
+	}
+}
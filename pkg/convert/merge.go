@@ -0,0 +1,39 @@
+package convert
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	OnDuplicateLatest = "latest"
+	OnDuplicateError  = "error"
+)
+
+// getDistinguishedDescription adds the input name relative to the common folder of all inputs
+// of the suite (without extension) to the description, so inputs with the same file name in
+// different folders (e.g. svc-a/report.json and svc-b/report.json) get different suites.
+func getDistinguishedDescription(description, inputName string, inputNames []string) string {
+	common := filepath.Dir(inputName)
+	for _, other := range inputNames {
+		for !isInFolder(other, common) {
+			parent := filepath.Dir(common)
+			if parent == common {
+				break
+			}
+			common = parent
+		}
+	}
+	name, err := filepath.Rel(common, inputName)
+	if err != nil {
+		name = inputName
+	}
+	name = filepath.ToSlash(strings.TrimSuffix(name, filepath.Ext(name)))
+	return fmt.Sprintf("%s [%s]", description, name)
+}
+
+func isInFolder(path, folder string) bool {
+	rel, err := filepath.Rel(folder, filepath.Dir(path))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
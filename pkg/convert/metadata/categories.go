@@ -0,0 +1,60 @@
+package metadata
+
+import (
+	"os"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	CategoriesFileName = "categories.json"
+)
+
+// Category is a rule of Allure failures classification. Allure checks rules in order
+// and puts the test into the first matched category. Regexes must match the whole text.
+type Category struct {
+	Name            string          `json:"name" yaml:"name"`
+	MatchedStatuses []allure.Status `json:"matchedStatuses,omitempty" yaml:"matchedStatuses,omitempty"`
+	MessageRegex    string          `json:"messageRegex,omitempty" yaml:"messageRegex,omitempty"`
+	TraceRegex      string          `json:"traceRegex,omitempty" yaml:"traceRegex,omitempty"`
+	Flaky           bool            `json:"flaky,omitempty" yaml:"flaky,omitempty"`
+}
+
+func GetDefaultCategories() []Category {
+	return []Category{{
+		Name:            "Suite setup failures",
+		MatchedStatuses: []allure.Status{allure.Broken},
+		MessageRegex:    "(?s)(BeforeSuite|SynchronizedBeforeSuite|ReportBeforeSuite) failed:.*",
+	}, {
+		Name:            "Timeouts",
+		MatchedStatuses: []allure.Status{allure.Broken, allure.Failed},
+		MessageRegex:    "(?is).*(timeout|timed out).*",
+	}, {
+		Name:            "Panics",
+		MatchedStatuses: []allure.Status{allure.Broken},
+		MessageRegex:    "(?is).*panic.*",
+	}, {
+		Name:            "Interrupted specs",
+		MatchedStatuses: []allure.Status{allure.Unknown},
+	}, {
+		Name:            "Assertion mismatches",
+		MatchedStatuses: []allure.Status{allure.Failed},
+		MessageRegex:    "(?s).*Expected.*",
+	}}
+}
+
+// LoadCategories reads categories from YAML or JSON file.
+func LoadCategories(filePath string) ([]Category, error) {
+	file, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot read categories file")
+	}
+	categories := []Category{}
+	err = yaml.Unmarshal(file, &categories)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed unmarshal categories")
+	}
+	return categories, nil
+}
@@ -0,0 +1,80 @@
+package metadata_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/metadata"
+	"github.com/google/uuid"
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadCategories(t *testing.T) {
+	expectedCategories := []metadata.Category{{
+		Name:            "Infrastructure",
+		MatchedStatuses: []allure.Status{allure.Broken},
+		MessageRegex:    ".*connection refused.*",
+	}}
+	var tests = []struct {
+		name    string
+		content string
+	}{{
+		name: "yaml",
+		content: `- name: Infrastructure
+  matchedStatuses: [broken]
+  messageRegex: .*connection refused.*
+`,
+	}, {
+		name:    "json",
+		content: `[{"name": "Infrastructure", "matchedStatuses": ["broken"], "messageRegex": ".*connection refused.*"}]`,
+	}}
+
+	for _, tt := range tests {
+		filePath := filepath.Join(os.TempDir(), fmt.Sprintf("categories-%s", uuid.New().String()))
+		err := os.WriteFile(filePath, []byte(tt.content), 0600)
+		assert.Empty(t, err, "categories file created")
+		categories, err := metadata.LoadCategories(filePath)
+		assert.Empty(t, err, fmt.Sprintf("categories loaded (%s)", tt.name))
+		assert.Equal(t, expectedCategories, categories, fmt.Sprintf("got expected categories (%s)", tt.name))
+	}
+
+	_, err := metadata.LoadCategories(filepath.Join(os.TempDir(), uuid.New().String()))
+	assert.Error(t, err, "not existing categories file")
+}
+
+func TestDefaultCategories(t *testing.T) {
+	var tests = []struct {
+		message  string
+		category string
+	}{{
+		message:  "BeforeSuite failed: connection refused",
+		category: "Suite setup failures",
+	}, {
+		message:  "A spec timeout occurred",
+		category: "Timeouts",
+	}, {
+		message:  "Test Panicked\nruntime error: invalid memory address",
+		category: "Panics",
+	}, {
+		message:  "Expected\n    <string>: 1\nto equal\n    <string>: 2",
+		category: "Assertion mismatches",
+	}}
+
+	for _, tt := range tests {
+		matched := ""
+		for _, category := range metadata.GetDefaultCategories() {
+			if category.MessageRegex == "" {
+				continue
+			}
+			if regexp.MustCompile("^(?:" + category.MessageRegex + ")$").MatchString(tt.message) {
+				matched = category.Name
+				break
+			}
+		}
+		assert.Equal(t, tt.category, matched, tt.message)
+	}
+}
@@ -0,0 +1,107 @@
+package metadata
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+const (
+	EnvironmentFileName = "environment.properties"
+	PropertySeparator   = "="
+)
+
+type Property struct {
+	Key   string
+	Value string
+}
+
+// ParseProperties parses `KEY=VALUE` pairs. The value may contain the separator.
+func ParseProperties(pairs []string) ([]Property, error) {
+	properties := make([]Property, 0, len(pairs))
+	for _, pair := range pairs {
+		keyValue := strings.SplitN(pair, PropertySeparator, 2)
+		if len(keyValue) != 2 || keyValue[0] == "" {
+			return nil, fmt.Errorf("incorrect environment property `%s`, expected KEY=VALUE", pair)
+		}
+		properties = append(properties, Property{Key: keyValue[0], Value: keyValue[1]})
+	}
+	return properties, nil
+}
+
+// GetSuiteProperties collects suite information from Ginkgo reports. Different values
+// of several reports are joined.
+func GetSuiteProperties(ginkgoReports []types.Report) []Property {
+	getters := []struct {
+		key   string
+		value func(r types.Report) string
+	}{
+		{"suite.path", func(r types.Report) string { return r.SuitePath }},
+		{"suite.description", func(r types.Report) string { return r.SuiteDescription }},
+		{"suite.run_time", func(r types.Report) string { return r.RunTime.String() }},
+		{"suite.total_specs", func(r types.Report) string { return strconv.Itoa(r.PreRunStats.TotalSpecs) }},
+		{"suite.specs_that_will_run", func(r types.Report) string {
+			return strconv.Itoa(r.PreRunStats.SpecsThatWillRun)
+		}},
+		{"ginkgo.random_seed", func(r types.Report) string { return strconv.FormatInt(r.SuiteConfig.RandomSeed, 10) }},
+		{"ginkgo.randomize_all_specs", func(r types.Report) string {
+			return strconv.FormatBool(r.SuiteConfig.RandomizeAllSpecs)
+		}},
+		{"ginkgo.label_filter", func(r types.Report) string { return r.SuiteConfig.LabelFilter }},
+		{"ginkgo.focus_strings", func(r types.Report) string { return strings.Join(r.SuiteConfig.FocusStrings, ",") }},
+		{"ginkgo.skip_strings", func(r types.Report) string { return strings.Join(r.SuiteConfig.SkipStrings, ",") }},
+		{"ginkgo.parallel_total", func(r types.Report) string { return strconv.Itoa(r.SuiteConfig.ParallelTotal) }},
+	}
+	properties := []Property{}
+	for _, getter := range getters {
+		values := []string{}
+		seen := map[string]bool{}
+		for _, ginkgoReport := range ginkgoReports {
+			value := getter.value(ginkgoReport)
+			if value == "" || seen[value] {
+				continue
+			}
+			seen[value] = true
+			values = append(values, value)
+		}
+		if len(values) != 0 {
+			properties = append(properties, Property{Key: getter.key, Value: strings.Join(values, ", ")})
+		}
+	}
+	return properties
+}
+
+// MergeProperties merges properties, later properties override earlier ones with the same key.
+func MergeProperties(propertiesList ...[]Property) []Property {
+	values := map[string]string{}
+	keys := []string{}
+	for _, properties := range propertiesList {
+		for _, property := range properties {
+			if _, ok := values[property.Key]; !ok {
+				keys = append(keys, property.Key)
+			}
+			values[property.Key] = property.Value
+		}
+	}
+	merged := make([]Property, 0, len(keys))
+	for _, key := range keys {
+		merged = append(merged, Property{Key: key, Value: values[key]})
+	}
+	return merged
+}
+
+// FormatProperties formats properties in the Java .properties format.
+func FormatProperties(properties []Property) []byte {
+	keyReplacer := strings.NewReplacer(`\`, `\\`, " ", `\ `, "=", `\=`, ":", `\:`, "\n", `\n`)
+	valueReplacer := strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`)
+	builder := strings.Builder{}
+	for _, property := range properties {
+		builder.WriteString(keyReplacer.Replace(property.Key))
+		builder.WriteString(PropertySeparator)
+		builder.WriteString(valueReplacer.Replace(property.Value))
+		builder.WriteString("\n")
+	}
+	return []byte(builder.String())
+}
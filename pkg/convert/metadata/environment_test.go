@@ -0,0 +1,56 @@
+package metadata_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/metadata"
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProperties(t *testing.T) {
+	properties, err := metadata.ParseProperties([]string{"cluster=kind", "url=https://test.local/?a=b"})
+	assert.Empty(t, err, "properties parsed")
+	assert.Equal(t, []metadata.Property{
+		{Key: "cluster", Value: "kind"},
+		{Key: "url", Value: "https://test.local/?a=b"},
+	}, properties, "got expected properties")
+
+	_, err = metadata.ParseProperties([]string{"incorrect"})
+	assert.Error(t, err, "property without separator")
+	_, err = metadata.ParseProperties([]string{"=value"})
+	assert.Error(t, err, "property without key")
+}
+
+func TestGetSuiteProperties(t *testing.T) {
+	ginkgoReports := []types.Report{{
+		SuitePath:        "/e2e/first",
+		SuiteDescription: "E2E",
+		RunTime:          time.Second,
+		SuiteConfig:      types.SuiteConfig{RandomSeed: 42, LabelFilter: "smoke", ParallelTotal: 4},
+	}, {
+		SuitePath:        "/e2e/second",
+		SuiteDescription: "E2E",
+		RunTime:          time.Second,
+		SuiteConfig:      types.SuiteConfig{RandomSeed: 42, LabelFilter: "smoke", ParallelTotal: 4},
+	}}
+	properties := metadata.GetSuiteProperties(ginkgoReports)
+	assert.Contains(t, properties, metadata.Property{Key: "suite.path", Value: "/e2e/first, /e2e/second"},
+		"different values joined")
+	assert.Contains(t, properties, metadata.Property{Key: "suite.description", Value: "E2E"}, "same values merged")
+	assert.Contains(t, properties, metadata.Property{Key: "ginkgo.random_seed", Value: "42"}, "random seed")
+	assert.Contains(t, properties, metadata.Property{Key: "ginkgo.label_filter", Value: "smoke"}, "label filter")
+	assert.Contains(t, properties, metadata.Property{Key: "ginkgo.parallel_total", Value: "4"}, "parallel total")
+	assert.NotContains(t, properties, metadata.Property{Key: "ginkgo.focus_strings", Value: ""},
+		"empty values skipped")
+}
+
+func TestMergeAndFormatProperties(t *testing.T) {
+	properties := metadata.MergeProperties(
+		[]metadata.Property{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}},
+		[]metadata.Property{{Key: "a", Value: "3"}, {Key: "c d", Value: "multi\nline"}},
+	)
+	assert.Equal(t, "a=3\nb=2\nc\\ d=multi\\nline\n", string(metadata.FormatProperties(properties)),
+		"properties merged and escaped")
+}
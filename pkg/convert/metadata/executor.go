@@ -0,0 +1,68 @@
+package metadata
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const (
+	ExecutorFileName = "executor.json"
+)
+
+// Executor describes CI build which produced results. Allure shows it in the Executors widget
+// and uses it to link reports of different builds.
+type Executor struct {
+	Name       string `json:"name,omitempty"`
+	Type       string `json:"type,omitempty"`
+	URL        string `json:"url,omitempty"`
+	BuildOrder int64  `json:"buildOrder,omitempty"`
+	BuildName  string `json:"buildName,omitempty"`
+	BuildURL   string `json:"buildUrl,omitempty"`
+	ReportURL  string `json:"reportUrl,omitempty"`
+	ReportName string `json:"reportName,omitempty"`
+}
+
+type GetEnvFunc func(key string) string
+
+// GetCIExecutor detects GitHub Actions, GitLab CI and Jenkins by their environment variables.
+func GetCIExecutor(getenv GetEnvFunc) (Executor, bool) {
+	switch {
+	case getenv("GITHUB_ACTIONS") == "true":
+		repositoryURL := fmt.Sprintf("%s/%s", getenv("GITHUB_SERVER_URL"), getenv("GITHUB_REPOSITORY"))
+		return Executor{
+			Name:       "GitHub Actions",
+			Type:       "github",
+			URL:        repositoryURL,
+			BuildOrder: parseBuildOrder(getenv("GITHUB_RUN_NUMBER")),
+			BuildName:  fmt.Sprintf("%s #%s", getenv("GITHUB_WORKFLOW"), getenv("GITHUB_RUN_NUMBER")),
+			BuildURL:   fmt.Sprintf("%s/actions/runs/%s", repositoryURL, getenv("GITHUB_RUN_ID")),
+		}, true
+	case getenv("GITLAB_CI") == "true":
+		return Executor{
+			Name:       "GitLab CI",
+			Type:       "gitlab",
+			URL:        getenv("CI_PROJECT_URL"),
+			BuildOrder: parseBuildOrder(getenv("CI_PIPELINE_IID")),
+			BuildName:  fmt.Sprintf("%s #%s", getenv("CI_JOB_NAME"), getenv("CI_PIPELINE_IID")),
+			BuildURL:   getenv("CI_PIPELINE_URL"),
+		}, true
+	case getenv("JENKINS_URL") != "":
+		return Executor{
+			Name:       "Jenkins",
+			Type:       "jenkins",
+			URL:        getenv("JENKINS_URL"),
+			BuildOrder: parseBuildOrder(getenv("BUILD_NUMBER")),
+			BuildName:  getenv("BUILD_DISPLAY_NAME"),
+			BuildURL:   getenv("BUILD_URL"),
+		}, true
+	}
+	return Executor{}, false
+}
+
+func parseBuildOrder(value string) int64 {
+	order, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return order
+}
@@ -0,0 +1,63 @@
+package metadata_test
+
+import (
+	"testing"
+
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCIExecutor(t *testing.T) {
+	var tests = []struct {
+		name     string
+		env      map[string]string
+		executor metadata.Executor
+		found    bool
+	}{{
+		name: "github actions",
+		env: map[string]string{
+			"GITHUB_ACTIONS":    "true",
+			"GITHUB_SERVER_URL": "https://github.com",
+			"GITHUB_REPOSITORY": "org/repo",
+			"GITHUB_WORKFLOW":   "e2e",
+			"GITHUB_RUN_NUMBER": "12",
+			"GITHUB_RUN_ID":     "345",
+		},
+		executor: metadata.Executor{
+			Name:       "GitHub Actions",
+			Type:       "github",
+			URL:        "https://github.com/org/repo",
+			BuildOrder: 12,
+			BuildName:  "e2e #12",
+			BuildURL:   "https://github.com/org/repo/actions/runs/345",
+		},
+		found: true,
+	}, {
+		name: "jenkins",
+		env: map[string]string{
+			"JENKINS_URL":        "https://jenkins.local/",
+			"BUILD_NUMBER":       "7",
+			"BUILD_DISPLAY_NAME": "#7",
+			"BUILD_URL":          "https://jenkins.local/job/e2e/7/",
+		},
+		executor: metadata.Executor{
+			Name:       "Jenkins",
+			Type:       "jenkins",
+			URL:        "https://jenkins.local/",
+			BuildOrder: 7,
+			BuildName:  "#7",
+			BuildURL:   "https://jenkins.local/job/e2e/7/",
+		},
+		found: true,
+	}, {
+		name:  "not ci",
+		env:   map[string]string{},
+		found: false,
+	}}
+
+	for _, tt := range tests {
+		executor, found := metadata.GetCIExecutor(func(key string) string { return tt.env[key] })
+		assert.Equal(t, tt.found, found, tt.name)
+		assert.Equal(t, tt.executor, executor, tt.name)
+	}
+}
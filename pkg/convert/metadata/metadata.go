@@ -0,0 +1,8 @@
+package metadata
+
+// Config contains metadata files content of Allure results folder.
+type Config struct {
+	Categories  []Category
+	Environment []Property
+	Executor    *Executor
+}
@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/pkg/errors"
+)
+
+// SpecFilter reports whether the It spec of the suite with the suite labels is converted.
+type SpecFilter func(suiteLabels []string, specReport types.SpecReport) bool
+
+var specStates = map[string]types.SpecState{}
+
+func init() {
+	for _, state := range []types.SpecState{types.SpecStatePending, types.SpecStateSkipped, types.SpecStatePassed,
+		types.SpecStateFailed, types.SpecStateAborted, types.SpecStatePanicked, types.SpecStateInterrupted,
+		types.SpecStateTimedout} {
+		specStates[state.String()] = state
+	}
+}
+
+// NewSpecFilter creates filter of specs by Ginkgo label filter query (e.g. `smoke && !flaky`)
+// over suite, container and spec labels and by spec states (e.g. failed, panicked). Empty query or
+// states match all specs.
+func NewSpecFilter(labelFilter string, states []string) (SpecFilter, error) {
+	matchLabels, err := types.ParseLabelFilter(labelFilter)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot parse label filter")
+	}
+	matchStates := types.SpecState(0)
+	for _, name := range states {
+		state, ok := specStates[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown spec state %s", name)
+		}
+		matchStates |= state
+	}
+	return func(suiteLabels []string, specReport types.SpecReport) bool {
+		if matchStates != 0 && !specReport.State.Is(matchStates) {
+			return false
+		}
+		return matchLabels(append(append([]string{}, suiteLabels...), specReport.Labels()...))
+	}, nil
+}
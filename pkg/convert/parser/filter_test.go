@@ -0,0 +1,64 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/parser"
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSpecFilter(t *testing.T) {
+	smoke := types.SpecReport{State: types.SpecStateFailed, LeafNodeLabels: []string{"id=1"},
+		ContainerHierarchyLabels: [][]string{{"smoke"}}}
+	flaky := types.SpecReport{State: types.SpecStatePassed, LeafNodeLabels: []string{"smoke", "flaky"}}
+	var tests = []struct {
+		name        string
+		labelFilter string
+		suiteLabels []string
+		states      []string
+		matches     []bool
+		isError     bool
+	}{{
+		name:    "match all",
+		matches: []bool{true, true},
+	}, {
+		name:        "label filter over container labels",
+		labelFilter: "smoke && !flaky",
+		matches:     []bool{true, false},
+	}, {
+		name:    "states",
+		states:  []string{"passed", "panicked"},
+		matches: []bool{false, true},
+	}, {
+		name:        "label filter and states",
+		labelFilter: "flaky",
+		states:      []string{"failed"},
+		matches:     []bool{false, false},
+	}, {
+		name:        "suite labels",
+		labelFilter: "e2e && smoke",
+		suiteLabels: []string{"e2e"},
+		matches:     []bool{true, true},
+	}, {
+		name:        "incorrect label filter",
+		labelFilter: "smoke &&",
+		isError:     true,
+	}, {
+		name:    "unknown state",
+		states:  []string{"broken"},
+		isError: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := parser.NewSpecFilter(tt.labelFilter, tt.states)
+			if tt.isError {
+				assert.Error(t, err, "incorrect filter")
+				return
+			}
+			assert.Empty(t, err, "correct filter")
+			assert.Equal(t, tt.matches, []bool{filter(tt.suiteLabels, smoke), filter(tt.suiteLabels, flaky)},
+				"specs matched")
+		})
+	}
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"github.com/Moon1706/ginkgo2allure/pkg/convert/report"
 	"github.com/Moon1706/ginkgo2allure/pkg/convert/transform"
+	"github.com/google/uuid"
 	"github.com/onsi/ginkgo/v2/types"
 	"github.com/ozontech/allure-go/pkg/allure"
 )
@@ -10,11 +11,14 @@ import (
 type (
 	Reporter interface {
 		GenerateAllureReport([]*allure.Step) (allure.Result, error)
+		GenerateAllureRetries(allure.Result, [][]*allure.Step) []allure.Result
 		SetLabelsScraper(ls report.LabelScraper)
 	}
 	Transformer interface {
 		AnalyzeEvents(types.SpecEvents, types.Failure) error
 		GetAllureSteps() []*allure.Step
+		GetAllureFixtures() (befores, afters []*allure.Step)
+		GetAllureAttempts() [][]*allure.Step
 	}
 	Parser struct {
 		Transformer  Transformer
@@ -26,6 +30,8 @@ type (
 		TransformOpts     []transform.Opt
 		LabelsScraperOpts []report.LabelsScraperOpt
 		ReportOpts        []report.Opt
+		// SpecFilter skips It specs which don't match, all specs are converted if it's nil.
+		SpecFilter SpecFilter
 	}
 	CreationFunc func(types.SpecReport, Config) (*Parser, error)
 )
@@ -42,9 +48,15 @@ func NewParser(specReport types.SpecReport, transformer Transformer,
 }
 
 func NewDefaultParser(specReport types.SpecReport, config Config) (*Parser, error) {
-	t := transform.NewTransform(config.TransformOpts...)
-	ls := report.NewLabelScraper(specReport.LeafNodeText, specReport.LeafNodeLabels, config.LabelsScraperOpts...)
+	scraperOpts := append([]report.LabelsScraperOpt{
+		report.WithContainerHierarchy(specReport.ContainerHierarchyTexts),
+		report.WithContainerLabels(specReport.ContainerHierarchyLabels),
+	}, config.LabelsScraperOpts...)
+	ls := report.NewLabelScraper(specReport.LeafNodeText, specReport.LeafNodeLabels, scraperOpts...)
 	r := report.NewReport(specReport, config.ReportOpts...)
+	transformOpts := append([]transform.Opt{}, config.TransformOpts...)
+	transformOpts = append(transformOpts, transform.WithStepHooks(r.AttachStepOutput, r.AttachStepEntries))
+	t := transform.NewTransform(transformOpts...)
 	return NewParser(specReport, t, ls, r), nil
 }
 
@@ -56,3 +68,24 @@ func (p *Parser) GetAllureReport() (allure.Result, error) {
 	steps := p.Transformer.GetAllureSteps()
 	return p.Reporter.GenerateAllureReport(steps)
 }
+
+// GetAllureContainer returns the container with setup and teardown fixtures of the spec.
+// It should be called after GetAllureReport. If the spec doesn't have fixtures, false is returned.
+func (p *Parser) GetAllureContainer(result allure.Result) (allure.Container, bool) {
+	befores, afters := p.Transformer.GetAllureFixtures()
+	container := allure.Container{
+		UUID:     uuid.New(),
+		Children: []uuid.UUID{result.UUID},
+		Befores:  befores,
+		Afters:   afters,
+		Start:    result.Start,
+		Stop:     result.Stop,
+	}
+	return container, !container.IsEmpty()
+}
+
+// GetAllureRetries returns results of previous attempts of the spec which share history
+// with the final result. It should be called after GetAllureReport.
+func (p *Parser) GetAllureRetries(result allure.Result) []allure.Result {
+	return p.Reporter.GenerateAllureRetries(result, p.Transformer.GetAllureAttempts())
+}
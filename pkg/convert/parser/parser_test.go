@@ -7,6 +7,7 @@ import (
 
 	"github.com/Moon1706/ginkgo2allure/pkg/convert/parser"
 	"github.com/Moon1706/ginkgo2allure/pkg/convert/report"
+	"github.com/google/uuid"
 	"github.com/onsi/ginkgo/v2/types"
 	"github.com/ozontech/allure-go/pkg/allure"
 	"github.com/stretchr/testify/assert"
@@ -19,13 +20,17 @@ var (
 type (
 	mockReport    struct{}
 	mockTransform struct {
-		Err error
+		Err     error
+		Befores []*allure.Step
 	}
 )
 
 func (m mockReport) GenerateAllureReport(_ []*allure.Step) (allure.Result, error) {
 	return allure.Result{}, nil
 }
+func (m mockReport) GenerateAllureRetries(_ allure.Result, _ [][]*allure.Step) []allure.Result {
+	return []allure.Result{}
+}
 func (m mockReport) SetLabelsScraper(_ report.LabelScraper) {}
 
 func (m mockTransform) AnalyzeEvents(_ types.SpecEvents, _ types.Failure) error {
@@ -34,6 +39,12 @@ func (m mockTransform) AnalyzeEvents(_ types.SpecEvents, _ types.Failure) error
 func (m mockTransform) GetAllureSteps() []*allure.Step {
 	return []*allure.Step{}
 }
+func (m mockTransform) GetAllureAttempts() [][]*allure.Step {
+	return [][]*allure.Step{}
+}
+func (m mockTransform) GetAllureFixtures() (befores, afters []*allure.Step) {
+	return m.Befores, nil
+}
 
 func TestParserGetAllureReport(t *testing.T) {
 	var tests = []struct {
@@ -72,3 +83,15 @@ func TestNewDefaultParser(t *testing.T) {
 	_, err := parser.NewDefaultParser(types.SpecReport{}, parser.Config{})
 	assert.Empty(t, err, "no error")
 }
+
+func TestParserGetAllureContainer(t *testing.T) {
+	result := allure.Result{UUID: uuid.New()}
+	p := parser.NewParser(types.SpecReport{}, mockTransform{}, nil, mockReport{})
+	_, ok := p.GetAllureContainer(result)
+	assert.False(t, ok, "container without fixtures")
+
+	p = parser.NewParser(types.SpecReport{}, mockTransform{Befores: []*allure.Step{{}}}, nil, mockReport{})
+	container, ok := p.GetAllureContainer(result)
+	assert.True(t, ok, "container with fixtures")
+	assert.Equal(t, []uuid.UUID{result.UUID}, container.Children, "container linked to result")
+}
@@ -0,0 +1,145 @@
+package convert
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+type (
+	pipelineTask struct {
+		seq     int
+		parse   func() specOutput
+		collect func(specOutput) error
+		output  specOutput
+	}
+	// pipeline parses specs with a pool of workers, collects the parsed specs in the order
+	// they were submitted and saves files with writers. Files with the same UUID are always
+	// saved by the same writer, so the output doesn't depend on the scheduling.
+	pipeline struct {
+		ctx       context.Context
+		cancel    context.CancelFunc
+		onErrs    func([]error)
+		seq       int
+		err       error
+		tasks     chan *pipelineTask
+		outputs   chan *pipelineTask
+		writes    []chan func() []error
+		workersWg sync.WaitGroup
+		writersWg sync.WaitGroup
+		collected chan struct{}
+	}
+)
+
+// newPipeline creates pipeline with the number of workers. With one worker or less
+// everything is done sequentially in the caller goroutine.
+func newPipeline(ctx context.Context, workers int, onErrs func([]error)) *pipeline {
+	p := &pipeline{onErrs: onErrs}
+	p.ctx, p.cancel = context.WithCancel(ctx)
+	if workers <= 1 {
+		return p
+	}
+	p.tasks = make(chan *pipelineTask, workers)
+	p.outputs = make(chan *pipelineTask, workers)
+	p.collected = make(chan struct{})
+	for i := 0; i < workers; i++ {
+		p.workersWg.Add(1)
+		go p.work()
+		write := make(chan func() []error, workers)
+		p.writes = append(p.writes, write)
+		p.writersWg.Add(1)
+		go p.save(write)
+	}
+	go p.collectOutputs()
+	return p
+}
+
+// submit schedules parsing (may be nil) and collecting of its output.
+func (p *pipeline) submit(parse func() specOutput, collect func(specOutput) error) error {
+	if err := p.ctx.Err(); err != nil {
+		return err
+	}
+	p.seq++
+	task := &pipelineTask{seq: p.seq, parse: parse, collect: collect}
+	if p.tasks == nil {
+		if parse != nil {
+			task.output = parse()
+		}
+		return collect(task.output)
+	}
+	select {
+	case p.tasks <- task:
+		return nil
+	case <-p.ctx.Done():
+		return p.ctx.Err()
+	}
+}
+
+// write saves files, key is UUID of the saved object.
+func (p *pipeline) write(key uuid.UUID, save func() []error) {
+	if p.writes == nil {
+		p.onErrs(save())
+		return
+	}
+	p.writes[int(key[len(key)-1])%len(p.writes)] <- save
+}
+
+// close waits for all submitted tasks and returns the first error.
+func (p *pipeline) close(err error) error {
+	defer p.cancel()
+	if p.tasks != nil {
+		close(p.tasks)
+		p.workersWg.Wait()
+		close(p.outputs)
+		<-p.collected
+		for _, write := range p.writes {
+			close(write)
+		}
+		p.writersWg.Wait()
+	}
+	if p.err != nil {
+		return p.err
+	}
+	if err != nil {
+		return err
+	}
+	return p.ctx.Err()
+}
+
+func (p *pipeline) work() {
+	defer p.workersWg.Done()
+	for task := range p.tasks {
+		if task.parse != nil && p.ctx.Err() == nil {
+			task.output = task.parse()
+		}
+		p.outputs <- task
+	}
+}
+
+func (p *pipeline) collectOutputs() {
+	defer close(p.collected)
+	pending := map[int]*pipelineTask{}
+	next := 1
+	for task := range p.outputs {
+		pending[task.seq] = task
+		for task, ok := pending[next]; ok; task, ok = pending[next] {
+			delete(pending, next)
+			next++
+			if p.err != nil || p.ctx.Err() != nil {
+				continue
+			}
+			if err := task.collect(task.output); err != nil {
+				p.err = err
+				p.cancel()
+			}
+		}
+	}
+}
+
+func (p *pipeline) save(write chan func() []error) {
+	defer p.writersWg.Done()
+	for save := range write {
+		p.onErrs(save())
+	}
+}
@@ -0,0 +1,172 @@
+package report
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/ozontech/allure-go/pkg/allure"
+)
+
+const (
+	DefaultAttachmentEntryPrefix = "attachment:"
+
+	// OctetStream is the type of binary attachments of unknown type, they are saved with
+	// binaryExtension, as Allure doesn't know the extension of this type.
+	OctetStream     allure.MimeType = "application/octet-stream"
+	binaryExtension                 = "bin"
+)
+
+var detectedMimeTypes = map[string]allure.MimeType{
+	"image/png":       allure.Png,
+	"image/jpeg":      allure.Jpg,
+	"image/gif":       allure.Gif,
+	"image/bmp":       allure.Bmp,
+	"application/pdf": allure.Pdf,
+	"video/mp4":       allure.Mp4,
+	"video/webm":      allure.Webm,
+	"text/html":       allure.HTML,
+	"text/xml":        allure.XML,
+}
+
+type reportEntryItems struct {
+	parameter  *allure.Parameter
+	link       *allure.Link
+	attachment *allure.Attachment
+}
+
+func WithAttachmentEntryPrefix(prefix string) Opt {
+	return func(o *DefaultReport) {
+		o.attachmentEntryPrefix = prefix
+	}
+}
+
+// AttachStepEntries binds report entries to the innermost step which was active
+// when the entry was added. Steps come in the order of their begin events, so the
+// last matched step is the innermost one.
+func (r *DefaultReport) AttachStepEntries(step *allure.Step, begin, end types.TimelineLocation) {
+	for i, entry := range r.specReport.ReportEntries {
+		order := entry.TimelineLocation.Order
+		if begin.Order < order && order < end.Order {
+			r.entriesSteps[i] = step
+		}
+	}
+}
+
+func (r *DefaultReport) addReportEntries(result *allure.Result) {
+	for i, entry := range r.specReport.ReportEntries {
+		items := r.convertReportEntry(entry)
+		if items.link != nil {
+			result.Links = append(result.Links, items.link)
+			continue
+		}
+		step, ok := r.entriesSteps[i]
+		switch {
+		case ok && items.parameter != nil:
+			step.Parameters = append(step.Parameters, items.parameter)
+		case ok && items.attachment != nil:
+			step.Attachments = append(step.Attachments, items.attachment)
+		case items.parameter != nil:
+			result.Parameters = append(result.Parameters, items.parameter)
+		case items.attachment != nil:
+			result.Attachments = append(result.Attachments, items.attachment)
+		}
+	}
+}
+
+func (r *DefaultReport) convertReportEntry(entry types.ReportEntry) reportEntryItems {
+	if r.attachmentEntryPrefix != "" && strings.HasPrefix(entry.Name, r.attachmentEntryPrefix) {
+		content := getReportEntryContent(entry)
+		return reportEntryItems{attachment: newAttachment(strings.TrimPrefix(entry.Name,
+			r.attachmentEntryPrefix), content)}
+	}
+	switch value := entry.GetRawValue().(type) {
+	case []byte, map[string]interface{}, []interface{}:
+		content := getReportEntryContent(entry)
+		return reportEntryItems{attachment: newAttachment(entry.Name, content)}
+	case string:
+		if content, ok := decodeBytesEntry(entry, value); ok {
+			return reportEntryItems{attachment: newAttachment(entry.Name, content)}
+		}
+		if isURL(value) {
+			return reportEntryItems{link: allure.LinkLink(entry.Name, value)}
+		}
+	}
+	return reportEntryItems{parameter: &allure.Parameter{
+		Name:  entry.Name,
+		Value: entry.StringRepresentation(),
+	}}
+}
+
+func getReportEntryContent(entry types.ReportEntry) []byte {
+	switch value := entry.GetRawValue().(type) {
+	case nil:
+		return []byte(entry.StringRepresentation())
+	case []byte:
+		return value
+	case string:
+		if content, ok := decodeBytesEntry(entry, value); ok {
+			return content
+		}
+		return []byte(value)
+	default:
+		content, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return []byte(entry.StringRepresentation())
+		}
+		return content
+	}
+}
+
+// decodeBytesEntry restores byte payloads from JSON reports. Ginkgo encodes []byte
+// values as base64 strings, but keeps the original `%+v` representation of bytes.
+func decodeBytesEntry(entry types.ReportEntry, value string) ([]byte, bool) {
+	representation := entry.Value.Representation
+	if !strings.HasPrefix(representation, "[") || representation == value {
+		return nil, false
+	}
+	content, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, false
+	}
+	return content, true
+}
+
+// newAttachment creates attachment of the type detected by the content.
+func newAttachment(name string, content []byte) *allure.Attachment {
+	mimeType := getMimeType(content)
+	attachment := allure.NewAttachment(name, mimeType, content)
+	if mimeType == OctetStream {
+		attachment.Source = strings.TrimSuffix(attachment.Source, ".") + "." + binaryExtension
+	}
+	return attachment
+}
+
+func getMimeType(content []byte) allure.MimeType {
+	trimmedContent := bytes.TrimSpace(content)
+	if len(trimmedContent) != 0 && (trimmedContent[0] == '{' || trimmedContent[0] == '[') &&
+		json.Valid(trimmedContent) {
+		return allure.JSON
+	}
+	detectedType := strings.Split(http.DetectContentType(content), ";")[0]
+	if mimeType, ok := detectedMimeTypes[detectedType]; ok {
+		return mimeType
+	}
+	if utf8.Valid(content) {
+		return allure.Text
+	}
+	return OctetStream
+}
+
+func isURL(value string) bool {
+	u, err := url.ParseRequestURI(value)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,81 @@
+package report_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/report"
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/stretchr/testify/assert"
+)
+
+func newReportEntry(t *testing.T, name string, value interface{}, order int) types.ReportEntry {
+	rawEntry, err := json.Marshal(types.ReportEntry{
+		Name:             name,
+		Value:            types.WrapEntryValue(value),
+		TimelineLocation: types.TimelineLocation{Order: order},
+	})
+	assert.Empty(t, err, "report entry marshaled")
+	entry := types.ReportEntry{}
+	err = json.Unmarshal(rawEntry, &entry)
+	assert.Empty(t, err, "report entry unmarshaled")
+	return entry
+}
+
+func TestGenerateAllureReportEntries(t *testing.T) {
+	specReport := types.SpecReport{ReportEntries: types.ReportEntries{
+		newReportEntry(t, "request id", "42", 1),
+		newReportEntry(t, "dashboard", "https://grafana.local/d/cluster?var=a", 2),
+		newReportEntry(t, "cluster", "kind", 11),
+	}}
+	r := report.NewReport(specReport)
+	r.SetLabelsScraper(report.NewLabelScraper("test", []string{}, report.WillAutoGenerateID(true)))
+
+	step := &allure.Step{}
+	r.AttachStepEntries(&allure.Step{}, types.TimelineLocation{Order: 0}, types.TimelineLocation{Order: 20})
+	r.AttachStepEntries(step, types.TimelineLocation{Order: 10}, types.TimelineLocation{Order: 12})
+	result, err := r.GenerateAllureReport([]*allure.Step{step})
+	assert.Empty(t, err, "allure report was created successful")
+
+	assert.Equal(t, []*allure.Link{allure.LinkLink("dashboard", "https://grafana.local/d/cluster?var=a")},
+		result.Links, "url entry converted to link")
+	assert.Equal(t, []*allure.Parameter{{Name: "cluster", Value: "kind"}}, step.Parameters,
+		"entry attached to the active step")
+	assert.Empty(t, result.Parameters, "entries attached to the outer step")
+}
+
+func TestConvertReportEntries(t *testing.T) {
+	specReport := types.SpecReport{ReportEntries: types.ReportEntries{
+		newReportEntry(t, "request id", "42", 1),
+		newReportEntry(t, report.DefaultAttachmentEntryPrefix+"log", "some log", 2),
+		newReportEntry(t, "payload", map[string]string{"key": "value"}, 3),
+		newReportEntry(t, "screenshot", []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}, 4),
+		newReportEntry(t, "dump", []byte{0x00, 0xff, 0xfe, 0x01}, 5),
+	}}
+	r := report.NewReport(specReport)
+	r.SetLabelsScraper(report.NewLabelScraper("test", []string{}, report.WillAutoGenerateID(true)))
+	result, err := r.GenerateAllureReport([]*allure.Step{})
+	assert.Empty(t, err, "allure report was created successful")
+
+	assert.Equal(t, []*allure.Parameter{{Name: "request id", Value: "42"}}, result.Parameters,
+		"string entry converted to parameter")
+	assert.Len(t, result.Attachments, 4, "entries converted to attachments")
+	assert.Equal(t, "log", result.Attachments[0].Name, "prefix trimmed from attachment name")
+	assert.Equal(t, allure.Text, result.Attachments[0].Type, "text attachment")
+	assert.Equal(t, []byte("some log"), result.Attachments[0].GetContent(), "text attachment content")
+	assert.Equal(t, allure.JSON, result.Attachments[1].Type, "json attachment")
+	assert.Equal(t, allure.Png, result.Attachments[2].Type, "bytes attachment")
+	assert.Equal(t, []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}, result.Attachments[2].GetContent(),
+		"bytes attachment content")
+	assert.Equal(t, report.OctetStream, result.Attachments[3].Type, "unknown binary attachment")
+	assert.True(t, strings.HasSuffix(result.Attachments[3].Source, "-attachment.bin"),
+		"unknown binary attachment has extension")
+
+	r = report.NewReport(specReport, report.WithAttachmentEntryPrefix(""))
+	r.SetLabelsScraper(report.NewLabelScraper("test", []string{}, report.WillAutoGenerateID(true)))
+	result, err = r.GenerateAllureReport([]*allure.Step{})
+	assert.Empty(t, err, "allure report was created successful")
+	assert.Len(t, result.Parameters, 2, "prefix disabled")
+}
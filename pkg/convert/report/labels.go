@@ -1,7 +1,10 @@
 package report
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/google/uuid"
@@ -11,9 +14,13 @@ import (
 const (
 	EpicLabelName        = "epic"
 	SuiteLabelName       = "suite"
+	ParentSuiteLabelName = "parentSuite"
+	SubSuiteLabelName    = "subSuite"
 	FeatureLabelName     = "feature"
 	IDLabelName          = "id"
 	DescriptionLabelName = "description"
+	TagLabelName         = "tag"
+	SeverityLabelName    = "severity"
 
 	DefaultLabelSpliter   = "="
 	DefaultEpic           = ""
@@ -21,16 +28,50 @@ const (
 	DefaultAutoGenerateID = false
 
 	CorrectCountLabelsParts = 2
+
+	// SuitesStrategyFlat sets only the suite label to the suite description.
+	SuitesStrategyFlat = "flat"
+	// SuitesStrategyPath maps the suite path (its last element), the suite description and
+	// the Describe/Context chain to parentSuite, suite and subSuite.
+	SuitesStrategyPath = "path"
+	// SuitesStrategyDescription maps the suite description, the first container and the rest
+	// of containers to parentSuite, suite and subSuite.
+	SuitesStrategyDescription = "description"
+	// SuitesStrategyContainers maps the first container, the second one and the rest of
+	// containers to parentSuite, suite and subSuite.
+	SuitesStrategyContainers = "containers"
+
+	DefaultSuitesStrategy  = SuitesStrategyFlat
+	DefaultSubSuitesJoiner = " / "
+
+	// Sources of labels from the lowest precedence to the highest one.
+	LabelSourceDefault   = "default"
+	LabelSourceSuite     = "suite"
+	LabelSourceContainer = "container"
+	LabelSourceLeaf      = "leaf"
 )
 
+// ErrMissingLabel is wrapped by errors of specs without mandatory labels (including
+// the id label), so such specs can be rejected without stopping the conversion.
+var ErrMissingLabel = errors.New("doesn't exist mandatory label")
+
 type (
 	DefaultLabelsScraper struct {
-		testName       string
-		epic           string
-		suiteName      string
-		testCaseLabels map[string]string
-		labelSpliter   string
-		autogenID      bool
+		testName        string
+		epic            string
+		suiteName       string
+		suitePath       string
+		containers      []string
+		suitesStrategy  string
+		suiteLabels     []string
+		containerLabels [][]string
+		debugf          func(template string, args ...interface{})
+		severityLabels  []string
+		linkTemplates   []LinkTemplate
+		testCaseLabels  map[string][]string
+		labelSources    map[string]string
+		labelSpliter    string
+		autogenID       bool
 	}
 	LabelsScraperOpt func(o *DefaultLabelsScraper)
 )
@@ -53,6 +94,59 @@ func WithSuiteName(suiteName string) LabelsScraperOpt {
 	}
 }
 
+func WithSuitePath(suitePath string) LabelsScraperOpt {
+	return func(o *DefaultLabelsScraper) {
+		o.suitePath = suitePath
+	}
+}
+
+// WithContainerHierarchy sets texts of Describe/Context containers of the spec.
+func WithContainerHierarchy(containers []string) LabelsScraperOpt {
+	return func(o *DefaultLabelsScraper) {
+		o.containers = containers
+	}
+}
+
+// WithSuitesStrategy sets how the suite path, the suite description and containers collapse
+// into parentSuite, suite and subSuite labels, see SuitesStrategy constants. The first
+// two levels are parentSuite and suite, the rest are joined into subSuite. Specs with fewer
+// levels get only suite (and parentSuite) labels.
+func WithSuitesStrategy(strategy string) LabelsScraperOpt {
+	return func(o *DefaultLabelsScraper) {
+		o.suitesStrategy = strategy
+	}
+}
+
+// WithSuiteLabels sets labels of the suite (RunSpecs), which are inherited by all specs.
+func WithSuiteLabels(labels []string) LabelsScraperOpt {
+	return func(o *DefaultLabelsScraper) {
+		o.suiteLabels = labels
+	}
+}
+
+// WithContainerLabels sets labels of Describe/Context containers of the spec from the outer
+// container to the inner one.
+func WithContainerLabels(labels [][]string) LabelsScraperOpt {
+	return func(o *DefaultLabelsScraper) {
+		o.containerLabels = labels
+	}
+}
+
+// WithDebugLog sets the function which logs the source of each label.
+func WithDebugLog(debugf func(template string, args ...interface{})) LabelsScraperOpt {
+	return func(o *DefaultLabelsScraper) {
+		o.debugf = debugf
+	}
+}
+
+// WithSeverityLabels sets bare labels (without separator), which are severity labels
+// instead of tags, e.g. critical or blocker.
+func WithSeverityLabels(labels []string) LabelsScraperOpt {
+	return func(o *DefaultLabelsScraper) {
+		o.severityLabels = labels
+	}
+}
+
 func WillAutoGenerateID(autogen bool) LabelsScraperOpt {
 	return func(o *DefaultLabelsScraper) {
 		o.autogenID = autogen
@@ -62,71 +156,223 @@ func WillAutoGenerateID(autogen bool) LabelsScraperOpt {
 func NewLabelScraper(leafNodeText string, leafNodeLabels []string,
 	scraperOptions ...LabelsScraperOpt) *DefaultLabelsScraper {
 	scraper := &DefaultLabelsScraper{
-		testName:     leafNodeText,
-		epic:         DefaultEpic,
-		suiteName:    DefaultSuiteName,
-		labelSpliter: DefaultLabelSpliter,
-		autogenID:    DefaultAutoGenerateID,
+		testName:       leafNodeText,
+		epic:           DefaultEpic,
+		suiteName:      DefaultSuiteName,
+		labelSpliter:   DefaultLabelSpliter,
+		autogenID:      DefaultAutoGenerateID,
+		suitesStrategy: DefaultSuitesStrategy,
 	}
 	for _, o := range scraperOptions {
 		o(scraper)
 	}
-	testCaseLabels := scraper.getAllTestCaseLabels(leafNodeLabels)
+	scraper.testCaseLabels = map[string][]string{}
+	scraper.labelSources = map[string]string{}
+	defaults := map[string][]string{}
+	for name, value := range scraper.getSuitesLabels() {
+		defaults[name] = []string{value}
+	}
 	if scraper.epic != "" {
-		testCaseLabels[EpicLabelName] = scraper.epic
+		defaults[EpicLabelName] = []string{scraper.epic}
 	}
-	if scraper.suiteName != "" {
-		testCaseLabels[SuiteLabelName] = scraper.suiteName
+	scraper.addLabels(defaults, LabelSourceDefault)
+	scraper.addLabels(scraper.getInheritedLabels(scraper.suiteLabels), LabelSourceSuite)
+	for _, labels := range scraper.containerLabels {
+		scraper.addLabels(scraper.getInheritedLabels(labels), LabelSourceContainer)
+	}
+	scraper.addLabels(scraper.getAllTestCaseLabels(leafNodeLabels), LabelSourceLeaf)
+	if scraper.debugf != nil {
+		for _, name := range sortedKeys(scraper.testCaseLabels) {
+			scraper.debugf("Spec `%s` label %s=%s from %s", scraper.testName, name,
+				strings.Join(scraper.testCaseLabels[name], ","), scraper.labelSources[name])
+		}
 	}
-	scraper.testCaseLabels = testCaseLabels
 	return scraper
 }
 
-func (ls *DefaultLabelsScraper) GetTestCaseLabels() map[string]string {
+// addLabels overrides labels of lower precedence, tags are added to tags of lower
+// precedence.
+func (ls *DefaultLabelsScraper) addLabels(labels map[string][]string, source string) {
+	for name, values := range labels {
+		if name == TagLabelName {
+			values = appendUnique(ls.testCaseLabels[name], values...)
+		}
+		ls.testCaseLabels[name] = values
+		ls.labelSources[name] = source
+	}
+}
+
+// getInheritedLabels parses labels of the suite or containers. The id label isn't inherited,
+// as it must be unique for each spec.
+func (ls *DefaultLabelsScraper) getInheritedLabels(labels []string) map[string][]string {
+	inherited := ls.getAllTestCaseLabels(labels)
+	delete(inherited, IDLabelName)
+	return inherited
+}
+
+// GetLabelSources returns the source (LabelSource constants) of each label.
+func (ls *DefaultLabelsScraper) GetLabelSources() map[string]string {
+	return ls.labelSources
+}
+
+// IsSuitesStrategy checks whether the strategy is known.
+func IsSuitesStrategy(strategy string) bool {
+	switch strategy {
+	case SuitesStrategyFlat, SuitesStrategyPath, SuitesStrategyDescription, SuitesStrategyContainers:
+		return true
+	}
+	return false
+}
+
+func (ls *DefaultLabelsScraper) getSuitesLabels() map[string]string {
+	levels := []string{}
+	switch ls.suitesStrategy {
+	case SuitesStrategyPath:
+		if ls.suitePath != "" {
+			levels = append(levels, filepath.Base(ls.suitePath))
+		}
+		levels = append(levels, ls.suiteName)
+		levels = append(levels, ls.containers...)
+	case SuitesStrategyDescription:
+		levels = append(levels, ls.suiteName)
+		levels = append(levels, ls.containers...)
+	case SuitesStrategyContainers:
+		levels = append(levels, ls.containers...)
+	}
+	levels = removeEmpty(levels)
+	if len(levels) == 0 {
+		levels = removeEmpty([]string{ls.suiteName})
+	}
+
+	labels := map[string]string{}
+	switch len(levels) {
+	case 0:
+	case 1:
+		labels[SuiteLabelName] = levels[0]
+	default:
+		labels[ParentSuiteLabelName] = levels[0]
+		labels[SuiteLabelName] = levels[1]
+		if len(levels) > 2 {
+			labels[SubSuiteLabelName] = strings.Join(levels[2:], DefaultSubSuitesJoiner)
+		}
+	}
+	return labels
+}
+
+func sortedKeys(values map[string][]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func removeEmpty(values []string) []string {
+	notEmpty := []string{}
+	for _, value := range values {
+		if value != "" {
+			notEmpty = append(notEmpty, value)
+		}
+	}
+	return notEmpty
+}
+
+// GetTestCaseLabels returns values of labels, repeated labels have several values.
+func (ls *DefaultLabelsScraper) GetTestCaseLabels() map[string][]string {
 	return ls.testCaseLabels
 }
 
-func (ls *DefaultLabelsScraper) getAllTestCaseLabels(labels []string) map[string]string {
-	labelsMap := map[string]string{}
+// getAllTestCaseLabels parses labels. Repeated labels keep all values, bare labels (without
+// separator) are tags or severity labels.
+func (ls *DefaultLabelsScraper) getAllTestCaseLabels(labels []string) map[string][]string {
+	labelsMap := map[string][]string{}
 	for _, label := range labels {
-		labelKeyValue := strings.Split(label, ls.labelSpliter)
-		if len(labelKeyValue) != CorrectCountLabelsParts {
+		if !strings.Contains(label, ls.labelSpliter) {
+			name := TagLabelName
+			if contains(ls.severityLabels, label) {
+				name = SeverityLabelName
+			}
+			labelsMap[name] = appendUnique(labelsMap[name], label)
 			continue
 		}
-		labelsMap[labelKeyValue[0]] = labelKeyValue[1]
+		labelKeyValue := strings.SplitN(label, ls.labelSpliter, CorrectCountLabelsParts)
+		labelsMap[labelKeyValue[0]] = appendUnique(labelsMap[labelKeyValue[0]], labelKeyValue[1])
 	}
 	return labelsMap
 }
 
+// getLabel returns the first value of the label.
+func (ls *DefaultLabelsScraper) getLabel(name string) (string, bool) {
+	values := ls.testCaseLabels[name]
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[0], true
+}
+
+func appendUnique(values []string, newValues ...string) []string {
+	values = append([]string{}, values...)
+	for _, value := range newValues {
+		if !contains(values, value) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 func (ls *DefaultLabelsScraper) CheckMandatoryLabels(mandatoryLabels []string) error {
 	for _, mandatoryLabel := range mandatoryLabels {
-		if _, ok := ls.testCaseLabels[mandatoryLabel]; !ok {
+		if _, ok := ls.getLabel(mandatoryLabel); !ok {
 			if mandatoryLabel == IDLabelName && ls.autogenID {
 				continue
 			}
-			return fmt.Errorf("doesn't exist mandatory label: %s", mandatoryLabel)
+			return fmt.Errorf("%w: %s", ErrMissingLabel, mandatoryLabel)
 		}
 	}
 	return nil
 }
 
+// CreateAllureLabels creates Allure label for each value of labels sorted by name.
 func (ls *DefaultLabelsScraper) CreateAllureLabels() (labels []*allure.Label) {
-	for key, value := range ls.testCaseLabels {
-		labels = append(labels, &allure.Label{
-			Name:  key,
-			Value: value,
-		})
+	for _, key := range sortedKeys(ls.testCaseLabels) {
+		for _, value := range ls.testCaseLabels[key] {
+			labels = append(labels, &allure.Label{
+				Name:  key,
+				Value: value,
+			})
+		}
 	}
 	return labels
 }
 
+// GetLabelValue returns the value of the first label with the name, or empty string.
+func GetLabelValue(labels []*allure.Label, name string) string {
+	for _, label := range labels {
+		if label != nil && label.Name == name {
+			return label.GetValue()
+		}
+	}
+	return ""
+}
+
 func (ls *DefaultLabelsScraper) GetID(defaultID uuid.UUID) (uuid.UUID, error) {
-	id, ok := ls.testCaseLabels[IDLabelName]
+	id, ok := ls.getLabel(IDLabelName)
 	if !ok {
 		if ls.autogenID {
 			return defaultID, nil
 		}
-		return uuid.UUID{}, fmt.Errorf("test with name `%s` doesn't contain UUID", ls.testName)
+		return uuid.UUID{}, fmt.Errorf("%w: %s, test with name `%s` doesn't contain UUID",
+			ErrMissingLabel, IDLabelName, ls.testName)
 	}
 	allureUUID, err := uuid.Parse(id)
 	if err != nil {
@@ -136,7 +382,7 @@ func (ls *DefaultLabelsScraper) GetID(defaultID uuid.UUID) (uuid.UUID, error) {
 }
 
 func (ls *DefaultLabelsScraper) GetDescription(defaultDescription string) string {
-	description, ok := ls.testCaseLabels[DescriptionLabelName]
+	description, ok := ls.getLabel(DescriptionLabelName)
 	if !ok {
 		description = defaultDescription
 	}
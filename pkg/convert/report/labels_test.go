@@ -18,37 +18,47 @@ func TestLabelScraperGetAllTestCaseLabels(t *testing.T) {
 	var tests = []struct {
 		name           string
 		leafNodeLabels []string
-		testCaseLabels map[string]string
+		testCaseLabels map[string][]string
 		scraperOpt     []report.LabelsScraperOpt
 	}{{
 		name:           "empty",
 		leafNodeLabels: []string{},
-		testCaseLabels: map[string]string{},
+		testCaseLabels: map[string][]string{},
 	}, {
-		name:           "incorrect label without spliter",
-		leafNodeLabels: []string{"incorrect-label"},
-		testCaseLabels: map[string]string{},
+		name:           "bare label without spliter is tag",
+		leafNodeLabels: []string{"smoke", "slow", "smoke"},
+		testCaseLabels: map[string][]string{report.TagLabelName: {"smoke", "slow"}},
 	}, {
-		name:           "incorrect label exist spliter, but many parts",
+		name:           "bare severity label",
+		leafNodeLabels: []string{"smoke", "critical"},
+		testCaseLabels: map[string][]string{report.TagLabelName: {"smoke"}, report.SeverityLabelName: {"critical"}},
+		scraperOpt:     []report.LabelsScraperOpt{report.WithSeverityLabels([]string{"critical", "blocker"})},
+	}, {
+		name: "repeated labels",
+		leafNodeLabels: []string{fmt.Sprintf("tag%ssmoke", report.DefaultLabelSpliter),
+			fmt.Sprintf("tag%sslow", report.DefaultLabelSpliter), "fast"},
+		testCaseLabels: map[string][]string{report.TagLabelName: {"smoke", "slow", "fast"}},
+	}, {
+		name:           "label value contains spliter",
 		leafNodeLabels: []string{fmt.Sprintf("multi%[1]sincorrect%[1]slabel", report.DefaultLabelSpliter)},
-		testCaseLabels: map[string]string{},
+		testCaseLabels: map[string][]string{"multi": {"incorrect=label"}},
 	}, {
 		name:           "correct label",
 		leafNodeLabels: []string{fmt.Sprintf("correct%slabel", report.DefaultLabelSpliter)},
-		testCaseLabels: map[string]string{"correct": "label"},
+		testCaseLabels: map[string][]string{"correct": {"label"}},
 	}, {
 		name:           "correct label with suite and epic labels",
 		leafNodeLabels: []string{fmt.Sprintf("correct%slabel", report.DefaultLabelSpliter)},
-		testCaseLabels: map[string]string{"correct": "label",
-			report.EpicLabelName:  "test",
-			report.SuiteLabelName: "test"},
+		testCaseLabels: map[string][]string{"correct": {"label"},
+			report.EpicLabelName:  {"test"},
+			report.SuiteLabelName: {"test"}},
 		scraperOpt: []report.LabelsScraperOpt{report.WithSuiteName("test"), report.WithEpic("test")},
 	}, {
 		name:           "change label spliter",
 		leafNodeLabels: []string{"correct:label"},
-		testCaseLabels: map[string]string{"correct": "label",
-			report.EpicLabelName:  "test",
-			report.SuiteLabelName: "test"},
+		testCaseLabels: map[string][]string{"correct": {"label"},
+			report.EpicLabelName:  {"test"},
+			report.SuiteLabelName: {"test"}},
 		scraperOpt: []report.LabelsScraperOpt{report.WithSuiteName("test"),
 			report.WithEpic("test"),
 			report.WithLabelSpliter(":")},
@@ -61,6 +71,105 @@ func TestLabelScraperGetAllTestCaseLabels(t *testing.T) {
 	}
 }
 
+func TestLabelScraperSuitesStrategy(t *testing.T) {
+	containers := []string{"Payments", "Refunds", "Partial"}
+	var tests = []struct {
+		name       string
+		strategy   string
+		containers []string
+		labels     map[string][]string
+	}{{
+		name:       "flat",
+		strategy:   report.SuitesStrategyFlat,
+		containers: containers,
+		labels:     map[string][]string{report.SuiteLabelName: {"E2E"}},
+	}, {
+		name:       "path",
+		strategy:   report.SuitesStrategyPath,
+		containers: containers,
+		labels: map[string][]string{report.ParentSuiteLabelName: {"e2e"}, report.SuiteLabelName: {"E2E"},
+			report.SubSuiteLabelName: {"Payments / Refunds / Partial"}},
+	}, {
+		name:       "description",
+		strategy:   report.SuitesStrategyDescription,
+		containers: containers,
+		labels: map[string][]string{report.ParentSuiteLabelName: {"E2E"}, report.SuiteLabelName: {"Payments"},
+			report.SubSuiteLabelName: {"Refunds / Partial"}},
+	}, {
+		name:       "description without containers",
+		strategy:   report.SuitesStrategyDescription,
+		containers: []string{},
+		labels:     map[string][]string{report.SuiteLabelName: {"E2E"}},
+	}, {
+		name:       "containers",
+		strategy:   report.SuitesStrategyContainers,
+		containers: containers[:2],
+		labels:     map[string][]string{report.ParentSuiteLabelName: {"Payments"}, report.SuiteLabelName: {"Refunds"}},
+	}, {
+		name:       "containers fall back to description",
+		strategy:   report.SuitesStrategyContainers,
+		containers: nil,
+		labels:     map[string][]string{report.SuiteLabelName: {"E2E"}},
+	}}
+
+	for _, tt := range tests {
+		lb := report.NewLabelScraper(testName, []string{}, report.WithSuiteName("E2E"),
+			report.WithSuitePath("/src/tests/e2e"), report.WithContainerHierarchy(tt.containers),
+			report.WithSuitesStrategy(tt.strategy))
+		assert.Equal(t, tt.labels, lb.GetTestCaseLabels(), tt.name)
+	}
+	assert.False(t, report.IsSuitesStrategy("deep"), "unknown strategy")
+}
+
+func TestLabelScraperInheritance(t *testing.T) {
+	logs := []string{}
+	lb := report.NewLabelScraper(testName, []string{"id=1", "owner=leaf"},
+		report.WithEpic("cli"),
+		report.WithSuiteName("E2E"),
+		report.WithSuiteLabels([]string{"epic=suite", "feature=suite", "owner=suite", "id=suite"}),
+		report.WithContainerLabels([][]string{{"feature=outer", "story=outer", "id=outer"}, {"story=inner"}}),
+		report.WithDebugLog(func(template string, args ...interface{}) {
+			logs = append(logs, fmt.Sprintf(template, args...))
+		}))
+	assert.Equal(t, map[string][]string{
+		report.IDLabelName:    {"1"},
+		report.SuiteLabelName: {"E2E"},
+		"epic":                {"suite"},
+		"feature":             {"outer"},
+		"story":               {"inner"},
+		"owner":               {"leaf"},
+	}, lb.GetTestCaseLabels(), "leaf overrides container overrides suite overrides CLI")
+	assert.Equal(t, map[string]string{
+		report.IDLabelName:    report.LabelSourceLeaf,
+		report.SuiteLabelName: report.LabelSourceDefault,
+		"epic":                report.LabelSourceSuite,
+		"feature":             report.LabelSourceContainer,
+		"story":               report.LabelSourceContainer,
+		"owner":               report.LabelSourceLeaf,
+	}, lb.GetLabelSources(), "label sources")
+	assert.Contains(t, logs, "Spec `test` label epic=suite from suite", "label source logged")
+
+	lb = report.NewLabelScraper(testName, []string{}, report.WithEpic("cli"),
+		report.WithContainerLabels([][]string{{"id=outer"}}))
+	assert.Equal(t, map[string][]string{"epic": {"cli"}}, lb.GetTestCaseLabels(), "CLI epic and id isn't inherited")
+}
+
+func TestLabelScraperInheritTags(t *testing.T) {
+	lb := report.NewLabelScraper(testName, []string{"leaf", "owner=leaf"},
+		report.WithSuiteLabels([]string{"e2e", "owner=suite", "owner=qa"}),
+		report.WithContainerLabels([][]string{{"payments", "e2e"}}))
+	assert.Equal(t, map[string][]string{
+		report.TagLabelName: {"e2e", "payments", "leaf"},
+		"owner":             {"leaf"},
+	}, lb.GetTestCaseLabels(), "tags of all levels merged, other labels overridden")
+	assert.Equal(t, []*allure.Label{
+		{Name: "owner", Value: "leaf"},
+		{Name: report.TagLabelName, Value: "e2e"},
+		{Name: report.TagLabelName, Value: "payments"},
+		{Name: report.TagLabelName, Value: "leaf"},
+	}, lb.CreateAllureLabels(), "label for each value")
+}
+
 func TestLabelScraperCheckMandatoryLabels(t *testing.T) {
 	mandatoryLabelsLabels := []string{report.IDLabelName}
 	lb := report.NewLabelScraper(testName, []string{fmt.Sprintf("%s%slabel", report.IDLabelName,
@@ -71,6 +180,7 @@ func TestLabelScraperCheckMandatoryLabels(t *testing.T) {
 	lb = report.NewLabelScraper(testName, []string{fmt.Sprintf("incorrect%slabel", report.DefaultLabelSpliter)})
 	err = lb.CheckMandatoryLabels(mandatoryLabelsLabels)
 	assert.Error(t, err, "lables wasn't found in madatory labels")
+	assert.ErrorIs(t, err, report.ErrMissingLabel, "missing label error")
 
 	lb = report.NewLabelScraper(testName, []string{}, report.WillAutoGenerateID(true))
 	err = lb.CheckMandatoryLabels(mandatoryLabelsLabels)
@@ -87,6 +197,12 @@ func TestLabelScraperCreateAllureLabels(t *testing.T) {
 	}}, allureLabels, "correctly convert map of strings to allure labels")
 }
 
+func TestGetLabelValue(t *testing.T) {
+	labels := []*allure.Label{nil, {Name: "tag", Value: "smoke"}, {Name: "tag", Value: "slow"}}
+	assert.Equal(t, "smoke", report.GetLabelValue(labels, "tag"), "first value returned")
+	assert.Equal(t, "", report.GetLabelValue(labels, "suite"), "missing label is empty")
+}
+
 func TestLabelGetID(t *testing.T) {
 	defaultID := uuid.MustParse("f67b2057-fc82-4dd7-bbd5-9d178aab9901")
 	var tests = []struct {
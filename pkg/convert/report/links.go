@@ -0,0 +1,69 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+)
+
+const (
+	LinkTemplatePlaceholder = "{}"
+
+	linkTemplateSeparator = "="
+	linkTypeSeparator     = ":"
+)
+
+// LinkTemplate turns values of the label into Allure links, the placeholder {} in the URL
+// is replaced with the label value.
+type LinkTemplate struct {
+	Label string
+	Type  allure.LinkTypes
+	URL   string
+}
+
+// ParseLinkTemplate parses link template `label[:type]=url`, e.g.
+// `jira:issue=https://jira.local/browse/{}`. The type is issue, tms or link. By default,
+// labels issue and tms make links of the same type, other labels make links of link type.
+func ParseLinkTemplate(value string) (LinkTemplate, error) {
+	name, url, ok := strings.Cut(value, linkTemplateSeparator)
+	if !ok || name == "" || url == "" {
+		return LinkTemplate{}, fmt.Errorf("link template %s isn't in format label[:type]=url", value)
+	}
+	if !strings.Contains(url, LinkTemplatePlaceholder) {
+		return LinkTemplate{}, fmt.Errorf("link template %s doesn't contain %s", value, LinkTemplatePlaceholder)
+	}
+	label, linkType, ok := strings.Cut(name, linkTypeSeparator)
+	if !ok {
+		linkType = label
+	}
+	template := LinkTemplate{Label: label, Type: allure.LinkTypes(linkType), URL: url}
+	switch template.Type {
+	case allure.ISSUE, allure.TMS, allure.LINK:
+	default:
+		if ok {
+			return LinkTemplate{}, fmt.Errorf("unknown link type %s, use %s, %s or %s", linkType,
+				allure.ISSUE, allure.TMS, allure.LINK)
+		}
+		template.Type = allure.LINK
+	}
+	return template, nil
+}
+
+// WithLinkTemplates sets templates of links created from labels.
+func WithLinkTemplates(templates []LinkTemplate) LabelsScraperOpt {
+	return func(o *DefaultLabelsScraper) {
+		o.linkTemplates = templates
+	}
+}
+
+// CreateAllureLinks creates Allure link for each value of labels which have link templates.
+func (ls *DefaultLabelsScraper) CreateAllureLinks() (links []*allure.Link) {
+	for _, template := range ls.linkTemplates {
+		for _, value := range ls.testCaseLabels[template.Label] {
+			url := strings.ReplaceAll(template.URL, LinkTemplatePlaceholder, value)
+			links = append(links, allure.NewLink(value, template.Type, url))
+		}
+	}
+	return links
+}
@@ -0,0 +1,65 @@
+package report_test
+
+import (
+	"testing"
+
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/report"
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLinkTemplate(t *testing.T) {
+	var tests = []struct {
+		name     string
+		value    string
+		template report.LinkTemplate
+		isError  bool
+	}{{
+		name:     "custom label is link",
+		value:    "docs=https://docs.local/{}",
+		template: report.LinkTemplate{Label: "docs", Type: allure.LINK, URL: "https://docs.local/{}"},
+	}, {
+		name:     "issue label is issue",
+		value:    "issue=https://jira.local/browse/{}",
+		template: report.LinkTemplate{Label: "issue", Type: allure.ISSUE, URL: "https://jira.local/browse/{}"},
+	}, {
+		name:     "explicit type",
+		value:    "jira:issue=https://jira.local/browse/{}?a=b",
+		template: report.LinkTemplate{Label: "jira", Type: allure.ISSUE, URL: "https://jira.local/browse/{}?a=b"},
+	}, {
+		name:    "unknown type",
+		value:   "jira:bug=https://jira.local/browse/{}",
+		isError: true,
+	}, {
+		name:    "without placeholder",
+		value:   "jira=https://jira.local/browse/",
+		isError: true,
+	}, {
+		name:    "without url",
+		value:   "jira",
+		isError: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template, err := report.ParseLinkTemplate(tt.value)
+			if tt.isError {
+				assert.Error(t, err, "incorrect link template")
+				return
+			}
+			assert.Empty(t, err, "correct link template")
+			assert.Equal(t, tt.template, template, "link template parsed")
+		})
+	}
+}
+
+func TestLabelScraperCreateAllureLinks(t *testing.T) {
+	jira, _ := report.ParseLinkTemplate("jira:issue=https://jira.local/browse/{}")
+	tms, _ := report.ParseLinkTemplate("tms=https://tms.local/case/{}")
+	lb := report.NewLabelScraper(testName, []string{"jira=PAY-1", "jira=PAY-2", "tms=a=b", "owner=qa"},
+		report.WithLinkTemplates([]report.LinkTemplate{jira, tms}))
+	assert.Equal(t, []*allure.Link{
+		allure.NewLink("PAY-1", allure.ISSUE, "https://jira.local/browse/PAY-1"),
+		allure.NewLink("PAY-2", allure.ISSUE, "https://jira.local/browse/PAY-2"),
+		allure.NewLink("a=b", allure.TMS, "https://tms.local/case/a=b"),
+	}, lb.CreateAllureLinks(), "link for each value of templated labels")
+}
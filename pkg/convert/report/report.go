@@ -4,6 +4,8 @@ import (
 	// #nosec
 	"crypto/md5"
 	"encoding/hex"
+	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
@@ -13,19 +15,45 @@ import (
 
 type (
 	DefaultReport struct {
-		mandatoryLabels []string
-		specReport      types.SpecReport
-		labelScraper    LabelScraper
+		mandatoryLabels       []string
+		attachmentEntryPrefix string
+		specReport            types.SpecReport
+		labelScraper          LabelScraper
+		entriesSteps          map[int]*allure.Step
 	}
 	LabelScraper interface {
 		CheckMandatoryLabels([]string) error
 		CreateAllureLabels() []*allure.Label
+		CreateAllureLinks() []*allure.Link
 		GetID(uuid.UUID) (uuid.UUID, error)
 		GetDescription(string) string
 	}
 	Opt func(o *DefaultReport)
 )
 
+const (
+	PendingStatusMessage     = "Spec is pending"
+	SkippedStatusMessage     = "Spec was skipped"
+	InterruptedStatusMessage = "Spec was interrupted"
+
+	FlakyTag             = "flaky"
+	AttemptFailurePrefix = "Failure recorded during attempt %d:\n"
+
+	GinkgoWriterAttachmentName = "GinkgoWriter output"
+	StdOutErrAttachmentName    = "Stdout/Stderr output"
+)
+
+var specStateStatuses = map[types.SpecState]allure.Status{
+	types.SpecStatePassed:      allure.Passed,
+	types.SpecStatePending:     allure.Skipped,
+	types.SpecStateSkipped:     allure.Skipped,
+	types.SpecStateFailed:      allure.Failed,
+	types.SpecStateAborted:     allure.Failed,
+	types.SpecStatePanicked:    allure.Broken,
+	types.SpecStateTimedout:    allure.Broken,
+	types.SpecStateInterrupted: allure.Unknown,
+}
+
 func WithMandatoryLabels(labels []string) Opt {
 	return func(o *DefaultReport) {
 		o.mandatoryLabels = labels
@@ -34,8 +62,10 @@ func WithMandatoryLabels(labels []string) Opt {
 
 func NewReport(specReport types.SpecReport, opts ...Opt) *DefaultReport {
 	r := &DefaultReport{
-		mandatoryLabels: []string{},
-		specReport:      specReport,
+		mandatoryLabels:       []string{},
+		attachmentEntryPrefix: DefaultAttachmentEntryPrefix,
+		specReport:            specReport,
+		entriesSteps:          map[int]*allure.Step{},
 	}
 	ls := NewLabelScraper(specReport.LeafNodeText, specReport.LeafNodeLabels)
 	r.SetLabelsScraper(ls)
@@ -65,19 +95,12 @@ func (r *DefaultReport) GenerateAllureReport(steps []*allure.Step) (allure.Resul
 		r.specReport.LeafNodeText), " ")
 	description := r.labelScraper.GetDescription(defaultDescription)
 
-	reportStatus := allure.Passed
-	statusDetails := allure.StatusDetail{}
-	if r.specReport.Failure.TimelineLocation.Order != 0 {
-		reportStatus = allure.Failed
-		statusDetails.Message = r.specReport.Failure.Message
-		statusDetails.Trace = r.specReport.Failure.Location.FullStackTrace
-	}
-	return allure.Result{
+	result := allure.Result{
 		Name:          r.specReport.LeafNodeText,
 		Description:   description,
 		FullName:      id.String(),
-		StatusDetails: statusDetails,
-		Status:        reportStatus,
+		StatusDetails: GetStatusDetails(r.specReport),
+		Status:        GetAllureStatus(r.specReport),
 		Start:         r.specReport.StartTime.UnixMilli(),
 		Stop:          r.specReport.EndTime.UnixMilli(),
 		Steps:         steps,
@@ -85,8 +108,183 @@ func (r *DefaultReport) GenerateAllureReport(steps []*allure.Step) (allure.Resul
 		TestCaseID:    testCaseID,
 		HistoryID:     GetMD5Hash(testCaseID),
 		Labels:        r.labelScraper.CreateAllureLabels(),
+		Links:         r.labelScraper.CreateAllureLinks(),
+		Attachments:   r.getOutputAttachments(),
 		ToPrint:       true,
-	}, nil
+	}
+	if r.isFlaky() {
+		result.Labels = append(result.Labels, allure.TagLabel(FlakyTag))
+	}
+	r.addReportEntries(&result)
+	return result, nil
+}
+
+// GenerateAllureRetries creates results for previous attempts of the spec. Retries share
+// TestCaseID and HistoryID with the final result, so Allure shows them in the retries tab.
+// UUIDs of retries are derived from the result UUID and the attempt number, so the same
+// retries get the same UUIDs on each conversion.
+func (r *DefaultReport) GenerateAllureRetries(result allure.Result, attempts [][]*allure.Step) []allure.Result {
+	retries := make([]allure.Result, 0, len(attempts))
+	for i, steps := range attempts {
+		retry := allure.Result{
+			Name:        result.Name,
+			Description: result.Description,
+			FullName:    result.FullName,
+			Status:      allure.Passed,
+			Steps:       steps,
+			UUID:        uuid.NewSHA1(result.UUID, []byte(strconv.Itoa(i+1))),
+			TestCaseID:  result.TestCaseID,
+			HistoryID:   result.HistoryID,
+			Labels:      r.getRetryLabels(result.Labels),
+			Links:       copyLinks(result.Links),
+			ToPrint:     true,
+		}
+		retry.Start, retry.Stop = getStepsTime(steps)
+		if r.specReport.MaxFlakeAttempts > 0 {
+			retry.Status = allure.Failed
+			if failure, ok := r.getAttemptFailure(i + 1); ok {
+				if status, ok := specStateStatuses[failure.State]; ok {
+					retry.Status = status
+				}
+				retry.StatusDetails = allure.StatusDetail{
+					Message: strings.TrimPrefix(failure.Failure.Message, fmt.Sprintf(AttemptFailurePrefix, i+1)),
+					Trace:   failure.Failure.Location.FullStackTrace,
+				}
+			}
+		}
+		retries = append(retries, retry)
+	}
+	return retries
+}
+
+// getRetryLabels copies labels of the final result without the flaky tag, as only the final
+// attempt of the flaky spec passed.
+func (r *DefaultReport) getRetryLabels(labels []*allure.Label) []*allure.Label {
+	flakyIdx := -1
+	if r.isFlaky() {
+		for i, label := range labels {
+			if label != nil && label.Name == TagLabelName && label.GetValue() == FlakyTag {
+				flakyIdx = i
+			}
+		}
+	}
+	retryLabels := make([]*allure.Label, 0, len(labels))
+	for i, label := range labels {
+		if label == nil || i == flakyIdx {
+			continue
+		}
+		label := *label
+		retryLabels = append(retryLabels, &label)
+	}
+	return retryLabels
+}
+
+func copyLinks(links []*allure.Link) []*allure.Link {
+	if links == nil {
+		return nil
+	}
+	retryLinks := make([]*allure.Link, 0, len(links))
+	for _, link := range links {
+		if link == nil {
+			continue
+		}
+		link := *link
+		retryLinks = append(retryLinks, &link)
+	}
+	return retryLinks
+}
+
+func (r *DefaultReport) isFlaky() bool {
+	return r.specReport.NumAttempts > 1 && r.specReport.MaxFlakeAttempts > 0 &&
+		r.specReport.State == types.SpecStatePassed
+}
+
+func (r *DefaultReport) getAttemptFailure(attempt int) (types.AdditionalFailure, bool) {
+	prefix := fmt.Sprintf(AttemptFailurePrefix, attempt)
+	for _, failure := range r.specReport.AdditionalFailures {
+		if strings.HasPrefix(failure.Failure.Message, prefix) {
+			return failure, true
+		}
+	}
+	return types.AdditionalFailure{}, false
+}
+
+func getStepsTime(steps []*allure.Step) (start, stop int64) {
+	for _, step := range steps {
+		if start == 0 || step.Start < start {
+			start = step.Start
+		}
+		if step.Stop > stop {
+			stop = step.Stop
+		}
+	}
+	return start, stop
+}
+
+func (r *DefaultReport) getOutputAttachments() (attachments []*allure.Attachment) {
+	if r.specReport.CapturedGinkgoWriterOutput != "" {
+		attachments = append(attachments, allure.NewAttachment(GinkgoWriterAttachmentName, allure.Text,
+			[]byte(r.specReport.CapturedGinkgoWriterOutput)))
+	}
+	if r.specReport.CapturedStdOutErr != "" {
+		attachments = append(attachments, allure.NewAttachment(StdOutErrAttachmentName, allure.Text,
+			[]byte(r.specReport.CapturedStdOutErr)))
+	}
+	return attachments
+}
+
+// AttachStepOutput attaches to the step without nested steps the part of GinkgoWriter
+// output which was written between step begin and end.
+func (r *DefaultReport) AttachStepOutput(step *allure.Step, begin, end types.TimelineLocation) {
+	// GinkgoWriter is truncated before each attempt, so offsets of retried specs
+	// don't match the captured output of all attempts.
+	output := r.specReport.CapturedGinkgoWriterOutput
+	if r.specReport.NumAttempts > 1 || len(step.Steps) != 0 || end.Offset > len(output) || begin.Offset >= end.Offset {
+		return
+	}
+	step.Attachments = append(step.Attachments, allure.NewAttachment(GinkgoWriterAttachmentName, allure.Text,
+		[]byte(output[begin.Offset:end.Offset])))
+}
+
+// GetAllureStatus maps the Ginkgo spec state to the Allure status. Reports without
+// a state (e.g. built by hand) fall back to the failure presence.
+func GetAllureStatus(specReport types.SpecReport) allure.Status {
+	if specReport.State == types.SpecStateInvalid {
+		if specReport.Failure.TimelineLocation.Order != 0 {
+			return allure.Failed
+		}
+		return allure.Passed
+	}
+	status, ok := specStateStatuses[specReport.State]
+	if !ok {
+		return allure.Unknown
+	}
+	return status
+}
+
+// GetStatusDetails returns the failure message and trace of the spec. Skipped and
+// pending specs get the skip reason, or a default one when Ginkgo didn't provide it.
+func GetStatusDetails(specReport types.SpecReport) allure.StatusDetail {
+	failure := specReport.Failure
+	statusDetails := allure.StatusDetail{
+		Message: failure.Message,
+		Trace:   failure.Location.FullStackTrace,
+	}
+	if failure.ForwardedPanic != "" {
+		statusDetails.Message = strings.TrimSpace(fmt.Sprintf("%s\n%s", failure.Message, failure.ForwardedPanic))
+	}
+	if statusDetails.Message != "" {
+		return statusDetails
+	}
+	switch specReport.State {
+	case types.SpecStatePending:
+		statusDetails.Message = PendingStatusMessage
+	case types.SpecStateSkipped:
+		statusDetails.Message = SkippedStatusMessage
+	case types.SpecStateInterrupted:
+		statusDetails.Message = InterruptedStatusMessage
+	}
+	return statusDetails
 }
 
 func GetMD5Hash(text string) string {
@@ -95,3 +95,173 @@ func TestGenerateAllureReport(t *testing.T) {
 			result.StatusDetails.Message, tt.name+" status message")
 	}
 }
+
+func TestGetAllureStatus(t *testing.T) {
+	var tests = []struct {
+		name          string
+		specReport    types.SpecReport
+		status        allure.Status
+		statusMessage string
+	}{{
+		name:       "passed",
+		specReport: types.SpecReport{State: types.SpecStatePassed},
+		status:     allure.Passed,
+	}, {
+		name:          "skipped by label filter",
+		specReport:    types.SpecReport{State: types.SpecStateSkipped},
+		status:        allure.Skipped,
+		statusMessage: report.SkippedStatusMessage,
+	}, {
+		name: "skipped with reason",
+		specReport: types.SpecReport{State: types.SpecStateSkipped, Failure: types.Failure{
+			Message: "not ready",
+		}},
+		status:        allure.Skipped,
+		statusMessage: "not ready",
+	}, {
+		name:          "pending",
+		specReport:    types.SpecReport{State: types.SpecStatePending},
+		status:        allure.Skipped,
+		statusMessage: report.PendingStatusMessage,
+	}, {
+		name: "failed",
+		specReport: types.SpecReport{State: types.SpecStateFailed, Failure: types.Failure{
+			Message: "Expected 1 to equal 2",
+		}},
+		status:        allure.Failed,
+		statusMessage: "Expected 1 to equal 2",
+	}, {
+		name: "panicked",
+		specReport: types.SpecReport{State: types.SpecStatePanicked, Failure: types.Failure{
+			Message:        "Test Panicked",
+			ForwardedPanic: "boom",
+		}},
+		status:        allure.Broken,
+		statusMessage: "Test Panicked\nboom",
+	}, {
+		name:       "timed out",
+		specReport: types.SpecReport{State: types.SpecStateTimedout},
+		status:     allure.Broken,
+	}, {
+		name:          "interrupted",
+		specReport:    types.SpecReport{State: types.SpecStateInterrupted},
+		status:        allure.Unknown,
+		statusMessage: report.InterruptedStatusMessage,
+	}, {
+		name: "without state",
+		specReport: types.SpecReport{Failure: types.Failure{
+			TimelineLocation: types.TimelineLocation{Order: 1},
+		}},
+		status: allure.Failed,
+	}}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.status, report.GetAllureStatus(tt.specReport), tt.name+" status")
+		assert.Equal(t, tt.statusMessage, report.GetStatusDetails(tt.specReport).Message, tt.name+" status message")
+	}
+}
+
+func TestGenerateAllureReportAttachments(t *testing.T) {
+	r := report.NewReport(types.SpecReport{
+		CapturedGinkgoWriterOutput: "writer",
+		CapturedStdOutErr:          "stdout",
+	}, report.WithMandatoryLabels([]string{}))
+	r.SetLabelsScraper(report.NewLabelScraper("test", []string{}, report.WillAutoGenerateID(true)))
+	result, err := r.GenerateAllureReport([]*allure.Step{})
+	assert.Empty(t, err, "allure report was created successful")
+	assert.Len(t, result.Attachments, 2, "both outputs attached")
+	assert.Equal(t, report.GinkgoWriterAttachmentName, result.Attachments[0].Name, "writer attachment name")
+	assert.Equal(t, []byte("writer"), result.Attachments[0].GetContent(), "writer attachment content")
+	assert.Equal(t, report.StdOutErrAttachmentName, result.Attachments[1].Name, "stdout attachment name")
+	assert.Equal(t, []byte("stdout"), result.Attachments[1].GetContent(), "stdout attachment content")
+}
+
+func TestAttachStepOutput(t *testing.T) {
+	r := report.NewReport(types.SpecReport{CapturedGinkgoWriterOutput: "first second"})
+	var tests = []struct {
+		name    string
+		step    *allure.Step
+		begin   int
+		end     int
+		content []byte
+	}{{
+		name:    "leaf step",
+		step:    &allure.Step{},
+		begin:   6,
+		end:     12,
+		content: []byte("second"),
+	}, {
+		name:  "step with nested steps",
+		step:  &allure.Step{Steps: []*allure.Step{{}}},
+		begin: 0,
+		end:   5,
+	}, {
+		name:  "step without output",
+		step:  &allure.Step{},
+		begin: 5,
+		end:   5,
+	}, {
+		name:  "offset out of output",
+		step:  &allure.Step{},
+		begin: 0,
+		end:   100,
+	}}
+
+	for _, tt := range tests {
+		r.AttachStepOutput(tt.step, types.TimelineLocation{Offset: tt.begin}, types.TimelineLocation{Offset: tt.end})
+		if tt.content == nil {
+			assert.Empty(t, tt.step.Attachments, tt.name)
+			continue
+		}
+		assert.Len(t, tt.step.Attachments, 1, tt.name)
+		assert.Equal(t, tt.content, tt.step.Attachments[0].GetContent(), tt.name)
+	}
+}
+
+func TestGenerateAllureRetries(t *testing.T) {
+	specReport := types.SpecReport{
+		LeafNodeText:     "test",
+		State:            types.SpecStatePassed,
+		NumAttempts:      3,
+		MaxFlakeAttempts: 3,
+		AdditionalFailures: []types.AdditionalFailure{{
+			State: types.SpecStateFailed,
+			Failure: types.Failure{
+				Message: fmt.Sprintf(report.AttemptFailurePrefix, 1) + "Expected 1 to equal 2",
+			},
+		}, {
+			Failure: types.Failure{
+				Message: fmt.Sprintf(report.AttemptFailurePrefix, 2) + "no state",
+			},
+		}},
+	}
+	r := report.NewReport(specReport)
+	r.SetLabelsScraper(report.NewLabelScraper("test", []string{"owner=qa"}, report.WillAutoGenerateID(true)))
+	result, err := r.GenerateAllureReport([]*allure.Step{})
+	assert.Empty(t, err, "allure report was created successful")
+	assert.Contains(t, result.Labels, allure.TagLabel(report.FlakyTag), "final attempt marked flaky")
+
+	retries := r.GenerateAllureRetries(result, [][]*allure.Step{{{Start: 1, Stop: 2}}, {}})
+	assert.Len(t, retries, 2, "two retries")
+	assert.Equal(t, result.Labels[:len(result.Labels)-1], retries[0].Labels, "retry labels without flaky tag")
+	assert.NotContains(t, retries[0].Labels, allure.TagLabel(report.FlakyTag), "retry isn't flaky")
+	retries[0].Labels[0].Value = "changed"
+	assert.NotEqual(t, "changed", result.Labels[0].Value, "retry labels are copied")
+	assert.Equal(t, allure.Failed, retries[1].Status, "attempt without state failed")
+	assert.NotEqual(t, result.UUID, retries[0].UUID, "retry has own UUID")
+	assert.NotEqual(t, retries[0].UUID, retries[1].UUID, "retries have different UUIDs")
+	assert.Equal(t, retries[0].UUID, r.GenerateAllureRetries(result, [][]*allure.Step{{}})[0].UUID,
+		"retry UUID is the same on each conversion")
+	assert.Equal(t, result.HistoryID, retries[0].HistoryID, "retry shares history")
+	assert.Equal(t, result.TestCaseID, retries[0].TestCaseID, "retry shares test case")
+	assert.Equal(t, allure.Failed, retries[0].Status, "retry failed")
+	assert.Equal(t, "Expected 1 to equal 2", retries[0].StatusDetails.Message, "retry failure message")
+	assert.Equal(t, int64(1), retries[0].Start, "retry start")
+	assert.Equal(t, int64(2), retries[0].Stop, "retry stop")
+
+	specReport.MaxFlakeAttempts = 0
+	specReport.MaxMustPassRepeatedly = 2
+	r = report.NewReport(specReport)
+	retries = r.GenerateAllureRetries(result, [][]*allure.Step{{}})
+	assert.Equal(t, allure.Passed, retries[0].Status, "repeated attempt passed")
+}
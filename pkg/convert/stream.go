@@ -0,0 +1,456 @@
+package convert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	fmngr "github.com/Moon1706/ginkgo2allure/pkg/convert/file_manager"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/input"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/parser"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/report"
+	"github.com/google/uuid"
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/pkg/errors"
+)
+
+const (
+	specReportsField = "SpecReports"
+)
+
+type (
+	// StreamConverter converts Ginkgo JSON reports spec by spec and saves each result as soon as
+	// it is converted, so memory usage doesn't depend on the report size.
+	StreamConverter struct {
+		fm             fmngr.FileManager
+		parserCreation parser.CreationFunc
+		config         parser.Config
+		onDuplicate    string
+		workers        int
+		inputFormat    string
+		rejectSpecs    bool
+		summary        *Summary
+		suitesInputs   map[string]map[string]bool
+		resultsStops   map[uuid.UUID]int64
+		attachments    map[uuid.UUID][]string
+		reports        []types.Report
+		saveErrs       []error
+		saveErrsMu     sync.Mutex
+	}
+	StreamConverterOpt func(c *StreamConverter)
+	reportCallbacks    struct {
+		suiteStarted  func(types.Report) error
+		spec          func(types.SpecReport) error
+		specSkipped   func() error
+		suiteFinished func(types.Report) error
+	}
+)
+
+// WithWorkers sets the number of goroutines which parse specs and save files.
+func WithWorkers(workers int) StreamConverterOpt {
+	return func(c *StreamConverter) {
+		c.workers = workers
+	}
+}
+
+// WithInputFormat sets the format of inputs: input.FormatJSON (by default) or input.FormatJUnit.
+// Only JSON reports are decoded spec by spec, other formats are decoded completely.
+func WithInputFormat(format string) StreamConverterOpt {
+	return func(c *StreamConverter) {
+		c.inputFormat = format
+	}
+}
+
+// WillRejectSpecsWithoutLabels skips specs without mandatory labels instead of stopping
+// the conversion, rejected specs are counted in the summary.
+func WillRejectSpecsWithoutLabels(reject bool) StreamConverterOpt {
+	return func(c *StreamConverter) {
+		c.rejectSpecs = reject
+	}
+}
+
+func NewStreamConverter(fm fmngr.FileManager, parserCreation parser.CreationFunc, config parser.Config,
+	onDuplicate string, opts ...StreamConverterOpt) *StreamConverter {
+	c := &StreamConverter{
+		fm:             fm,
+		parserCreation: parserCreation,
+		config:         config,
+		onDuplicate:    onDuplicate,
+		workers:        1,
+		inputFormat:    input.FormatJSON,
+		summary:        NewSummary(),
+		suitesInputs:   map[string]map[string]bool{},
+		resultsStops:   map[uuid.UUID]int64{},
+		attachments:    map[uuid.UUID][]string{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ScanSuites reads suite descriptions of the input without decoding specs. Suites which have
+// the same description in several scanned inputs get the input name during conversion, so
+// a single input doesn't have to be scanned. Context cancellation stops the scan.
+func (c *StreamConverter) ScanSuites(ctx context.Context, r io.Reader, inputName string) error {
+	return c.decodeInput(r, reportCallbacks{
+		specSkipped: ctx.Err,
+		suiteFinished: func(report types.Report) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if c.suitesInputs[report.SuiteDescription] == nil {
+				c.suitesInputs[report.SuiteDescription] = map[string]bool{}
+			}
+			c.suitesInputs[report.SuiteDescription][inputName] = true
+			return nil
+		},
+	})
+}
+
+// Convert converts and saves all specs of the input. Conversion errors and context cancellation
+// stop the conversion, saving errors are collected and available with GetSaveErrors.
+func (c *StreamConverter) Convert(ctx context.Context, r io.Reader, inputName string) error {
+	p := newPipeline(ctx, c.workers, c.addSaveErrors)
+	var suite *suiteConverter
+	err := c.decodeInput(r, reportCallbacks{
+		suiteStarted: func(report types.Report) error {
+			report.SuiteDescription = c.getSuiteDescription(report, inputName)
+			suite = newSuiteConverter(report, c.parserCreation, c.config)
+			suite.summary = c.summary
+			return nil
+		},
+		spec: func(specReport types.SpecReport) error {
+			if specReport.LeafNodeType != types.NodeTypeIt {
+				c.summary.AddNotIt()
+			}
+			parse, err := suite.prepareSpec(specReport)
+			if err != nil || parse == nil {
+				return err
+			}
+			s := suite
+			return p.submit(parse, func(output specOutput) error {
+				return c.collectSpec(s, output, p)
+			})
+		},
+		suiteFinished: func(report types.Report) error {
+			s := suite
+			return p.submit(nil, func(specOutput) error {
+				c.finishSuite(s, report, inputName, p)
+				return nil
+			})
+		},
+	})
+	return p.close(err)
+}
+
+// GetReports returns converted suites without spec reports.
+func (c *StreamConverter) GetReports() []types.Report {
+	return c.reports
+}
+
+func (c *StreamConverter) GetSaveErrors() []error {
+	return c.saveErrs
+}
+
+// GetSummary returns counts of converted results and specs which weren't converted.
+func (c *StreamConverter) GetSummary() *Summary {
+	return c.summary
+}
+
+func (c *StreamConverter) addSaveErrors(errs []error) {
+	if len(errs) == 0 {
+		return
+	}
+	c.saveErrsMu.Lock()
+	defer c.saveErrsMu.Unlock()
+	c.saveErrs = append(c.saveErrs, errs...)
+}
+
+func (c *StreamConverter) getSuiteDescription(report types.Report, inputName string) string {
+	inputs := c.suitesInputs[report.SuiteDescription]
+	if len(inputs) > 1 {
+		inputNames := make([]string, 0, len(inputs))
+		for name := range inputs {
+			inputNames = append(inputNames, name)
+		}
+		return getDistinguishedDescription(report.SuiteDescription, inputName, inputNames)
+	}
+	return report.SuiteDescription
+}
+
+func (c *StreamConverter) collectSpec(suite *suiteConverter, output specOutput, p *pipeline) error {
+	if output.err != nil {
+		if c.rejectSpecs && errors.Is(output.err, report.ErrMissingLabel) {
+			c.summary.AddRejected(output.err)
+			return nil
+		}
+		return output.err
+	}
+	for _, result := range append(output.retries, output.result) {
+		save, err := c.isResultLatest(result)
+		if err != nil {
+			return err
+		}
+		if save && result.UUID == output.result.UUID {
+			c.summary.AddResult(result)
+		}
+		if save {
+			result := result
+			superseded := c.attachments[result.UUID]
+			c.setResultAttachments(result)
+			p.write(result.UUID, func() []error {
+				return append(removeAttachments(superseded, c.fm), printAllureReport(result, c.fm)...)
+			})
+		}
+	}
+	for _, container := range output.containers {
+		container := container
+		p.write(container.UUID, func() []error {
+			return PrintAllureContainers([]allure.Container{container}, c.fm)
+		})
+	}
+	suite.addChild(output.result)
+	return nil
+}
+
+func (c *StreamConverter) finishSuite(suite *suiteConverter, report types.Report, inputName string, p *pipeline) {
+	if container, ok := suite.getContainer(report); ok {
+		p.write(container.UUID, func() []error {
+			return PrintAllureContainers([]allure.Container{container}, c.fm)
+		})
+	}
+	report.SuiteDescription = c.getSuiteDescription(report, inputName)
+	c.reports = append(c.reports, report)
+}
+
+// setResultAttachments remembers sources of attachments of the saved result, they are removed
+// when the result is replaced by the latest one.
+func (c *StreamConverter) setResultAttachments(result allure.Result) {
+	sources := []string{}
+	for _, attachment := range GetAllAttachments(result) {
+		sources = append(sources, attachment.Source)
+	}
+	if len(sources) == 0 {
+		delete(c.attachments, result.UUID)
+		return
+	}
+	c.attachments[result.UUID] = sources
+}
+
+func (c *StreamConverter) isResultLatest(result allure.Result) (bool, error) {
+	stop, ok := c.resultsStops[result.UUID]
+	if ok {
+		if c.onDuplicate != OnDuplicateLatest {
+			return false, fmt.Errorf("results with the same UUID: %s", result.UUID)
+		}
+		if result.Stop < stop {
+			return false, nil
+		}
+	}
+	c.resultsStops[result.UUID] = result.Stop
+	return true, nil
+}
+
+func (c *StreamConverter) decodeInput(r io.Reader, callbacks reportCallbacks) error {
+	if c.inputFormat == input.FormatJSON || c.inputFormat == "" {
+		return decodeGinkgoReports(r, callbacks)
+	}
+	ginkgoReports, err := input.DecodeReports(r, c.inputFormat)
+	if err != nil {
+		return err
+	}
+	return walkGinkgoReports(ginkgoReports, callbacks)
+}
+
+// walkGinkgoReports calls callbacks for already decoded reports in the same order as
+// decodeGinkgoReports does.
+func walkGinkgoReports(ginkgoReports []types.Report, callbacks reportCallbacks) error {
+	for _, ginkgoReport := range ginkgoReports {
+		specReports := ginkgoReport.SpecReports
+		ginkgoReport.SpecReports = nil
+		if callbacks.suiteStarted != nil {
+			err := callbacks.suiteStarted(ginkgoReport)
+			if err != nil {
+				return err
+			}
+		}
+		for _, specReport := range specReports {
+			if callbacks.spec == nil {
+				break
+			}
+			err := callbacks.spec(specReport)
+			if err != nil {
+				return err
+			}
+		}
+		if callbacks.suiteFinished != nil {
+			err := callbacks.suiteFinished(ginkgoReport)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func decodeGinkgoReports(r io.Reader, callbacks reportCallbacks) error {
+	dec := json.NewDecoder(r)
+	err := expectDelim(dec, '[')
+	if err != nil {
+		return err
+	}
+	for dec.More() {
+		err = decodeGinkgoReport(dec, callbacks)
+		if err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+func decodeGinkgoReport(dec *json.Decoder, callbacks reportCallbacks) error {
+	err := expectDelim(dec, '{')
+	if err != nil {
+		return err
+	}
+	fields := map[string]json.RawMessage{}
+	started := false
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return errors.Wrap(err, "Failed decode Report")
+		}
+		key, _ := token.(string)
+		if key != specReportsField {
+			var value json.RawMessage
+			err = dec.Decode(&value)
+			if err != nil {
+				return errors.Wrapf(err, "Failed decode Report field %s", key)
+			}
+			fields[key] = value
+			continue
+		}
+		err = startSuite(fields, callbacks)
+		if err != nil {
+			return err
+		}
+		started = true
+		err = decodeSpecReports(dec, callbacks)
+		if err != nil {
+			return err
+		}
+	}
+	err = expectDelim(dec, '}')
+	if err != nil {
+		return err
+	}
+	if !started {
+		err = startSuite(fields, callbacks)
+		if err != nil {
+			return err
+		}
+	}
+	report, err := unmarshalReportFields(fields)
+	if err != nil || callbacks.suiteFinished == nil {
+		return err
+	}
+	return callbacks.suiteFinished(report)
+}
+
+func startSuite(fields map[string]json.RawMessage, callbacks reportCallbacks) error {
+	if callbacks.suiteStarted == nil {
+		return nil
+	}
+	report, err := unmarshalReportFields(fields)
+	if err != nil {
+		return err
+	}
+	return callbacks.suiteStarted(report)
+}
+
+// decodeSpecReports decodes specs one by one, if there is no spec callback specs are skipped
+// and specSkipped is called for each of them.
+func decodeSpecReports(dec *json.Decoder, callbacks reportCallbacks) error {
+	token, err := dec.Token()
+	if err != nil {
+		return errors.Wrap(err, "Failed decode SpecReports")
+	}
+	if token == nil {
+		return nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("unexpected SpecReports token: %v", token)
+	}
+	for dec.More() {
+		if callbacks.spec == nil {
+			err = skipValue(dec)
+			if err == nil && callbacks.specSkipped != nil {
+				err = callbacks.specSkipped()
+			}
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		specReport := types.SpecReport{}
+		err = dec.Decode(&specReport)
+		if err != nil {
+			return errors.Wrap(err, "Failed decode SpecReport")
+		}
+		err = callbacks.spec(specReport)
+		if err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+// skipValue reads the next value token by token without keeping it in memory.
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return errors.Wrap(err, "Failed skip value")
+		}
+		if delim, ok := token.(json.Delim); ok {
+			switch delim {
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+func unmarshalReportFields(fields map[string]json.RawMessage) (types.Report, error) {
+	report := types.Report{}
+	bFields, err := json.Marshal(fields)
+	if err != nil {
+		return report, errors.Wrap(err, "Failed marshal Report fields")
+	}
+	err = json.Unmarshal(bFields, &report)
+	if err != nil {
+		return report, errors.Wrap(err, "Failed unmarshal Report")
+	}
+	return report, nil
+}
+
+func expectDelim(dec *json.Decoder, expected json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return errors.Wrap(err, "Failed decode Ginkgo report")
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != expected {
+		return fmt.Errorf("unexpected token %v, expected %v", token, expected)
+	}
+	return nil
+}
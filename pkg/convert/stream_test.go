@@ -0,0 +1,297 @@
+package convert_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Moon1706/ginkgo2allure/pkg/convert"
+	fmngr "github.com/Moon1706/ginkgo2allure/pkg/convert/file_manager"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/metadata"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/parser"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/report"
+	"github.com/google/uuid"
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	namedReport struct {
+		Name string
+		Stop int64
+	}
+	recordFileManager struct {
+		mu         sync.Mutex
+		Results    []allure.Result
+		Containers []allure.Container
+	}
+)
+
+func (m namedReport) GenerateAllureReport(_ []*allure.Step) (allure.Result, error) {
+	return allure.Result{UUID: uuid.NewSHA1(uuid.Nil, []byte(m.Name)), Name: m.Name, Stop: m.Stop}, nil
+}
+func (m namedReport) GenerateAllureRetries(_ allure.Result, _ [][]*allure.Step) []allure.Result {
+	return []allure.Result{}
+}
+func (m namedReport) SetLabelsScraper(_ report.LabelScraper) {}
+
+func (m *recordFileManager) SaveJSONResult(result allure.Result) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Results = append(m.Results, result)
+	return nil
+}
+func (m *recordFileManager) SaveAttachment(_ *allure.Attachment) error { return nil }
+func (m *recordFileManager) SaveJSONContainer(container allure.Container) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Containers = append(m.Containers, container)
+	return nil
+}
+func (m *recordFileManager) SaveCategories(_ []metadata.Category) error  { return nil }
+func (m *recordFileManager) SaveEnvironment(_ []metadata.Property) error { return nil }
+func (m *recordFileManager) SaveExecutor(_ metadata.Executor) error      { return nil }
+
+func namedParser(specReport types.SpecReport, _ parser.Config) (*parser.Parser, error) {
+	stop := specReport.EndTime.UnixMilli()
+	return parser.NewParser(specReport, mockTransform{}, nil, namedReport{Name: specReport.LeafNodeText, Stop: stop}), nil
+}
+
+func TestStreamConverterConvert(t *testing.T) {
+	input := `[{"SuiteDescription": "E2E", "SuitePath": "/e2e", "SpecReports": [
+		{"LeafNodeType": "It", "LeafNodeText": "first", "EndTime": "2024-01-01T00:00:01Z"},
+		{"LeafNodeType": "BeforeSuite", "State": "passed"},
+		{"LeafNodeType": "BeforeEach", "LeafNodeText": "skipped"},
+		{"LeafNodeType": "It", "LeafNodeText": "second", "EndTime": "2024-01-01T00:00:01Z"}
+	], "SpecialSuiteFailureReasons": null}, {"SpecReports": null, "SuiteDescription": "Empty"}]`
+
+	fm := &recordFileManager{}
+	converter := convert.NewStreamConverter(fm, namedParser, parser.Config{}, convert.OnDuplicateLatest)
+	err := converter.Convert(context.Background(), strings.NewReader(input), "e2e.json")
+	assert.Empty(t, err, "no conversion error")
+	assert.Empty(t, converter.GetSaveErrors(), "no saving errors")
+	assert.Equal(t, []string{"first", "second"}, []string{fm.Results[0].Name, fm.Results[1].Name},
+		"It specs saved in order")
+	assert.Len(t, fm.Containers, 1, "suite container saved")
+	assert.Equal(t, []uuid.UUID{fm.Results[0].UUID, fm.Results[1].UUID}, fm.Containers[0].Children,
+		"suite container children")
+
+	reports := converter.GetReports()
+	assert.Len(t, reports, 2, "all suites returned")
+	assert.Equal(t, "E2E", reports[0].SuiteDescription, "suite fields decoded")
+	assert.Equal(t, "/e2e", reports[0].SuitePath, "suite fields decoded after specs")
+	assert.Empty(t, reports[0].SpecReports, "specs not kept")
+	assert.Equal(t, "Empty", reports[1].SuiteDescription, "suite without specs decoded")
+}
+
+func TestStreamConverterJUnitInput(t *testing.T) {
+	input := `<testsuites><testsuite name="E2E" package="/e2e" timestamp="2024-01-01T00:00:00">
+		<testcase name="[BeforeSuite]" status="passed" time="1"></testcase>
+		<testcase name="[It] first [id=1]" status="passed" time="1"></testcase>
+		<testcase name="[It] second" status="failed" time="1"><failure message="fail"></failure></testcase>
+	</testsuite></testsuites>`
+
+	fm := &recordFileManager{}
+	converter := convert.NewStreamConverter(fm, namedParser, parser.Config{}, convert.OnDuplicateLatest,
+		convert.WithInputFormat("junit"))
+	assert.Empty(t, converter.ScanSuites(context.Background(), strings.NewReader(input), "e2e.xml"), "no scan error")
+	err := converter.Convert(context.Background(), strings.NewReader(input), "e2e.xml")
+	assert.Empty(t, err, "no conversion error")
+	assert.Equal(t, []string{"first", "second"}, []string{fm.Results[0].Name, fm.Results[1].Name},
+		"It specs saved in order")
+	assert.Len(t, fm.Containers, 1, "suite container saved")
+
+	reports := converter.GetReports()
+	assert.Len(t, reports, 1, "suite returned")
+	assert.Equal(t, "/e2e", reports[0].SuitePath, "suite fields decoded")
+	assert.Empty(t, reports[0].SpecReports, "specs not kept")
+
+	err = convert.NewStreamConverter(fm, namedParser, parser.Config{}, convert.OnDuplicateLatest,
+		convert.WithInputFormat("junit")).Convert(context.Background(), strings.NewReader("[]"), "e2e.json")
+	assert.Error(t, err, "JSON report isn't JUnit report")
+}
+
+func TestStreamConverterDuplicates(t *testing.T) {
+	inputs := []string{
+		`[{"SuiteDescription": "E2E", "SpecReports": [{"LeafNodeType": "It", "LeafNodeText": "spec",
+			"EndTime": "2024-01-01T00:00:02Z"}]}]`,
+		`[{"SuiteDescription": "E2E", "SpecReports": [{"LeafNodeType": "It", "LeafNodeText": "spec",
+			"EndTime": "2024-01-01T00:00:01Z"}]}]`,
+	}
+	names := []string{"payments.json", "orders.json"}
+
+	fm := &recordFileManager{}
+	converter := convert.NewStreamConverter(fm, namedParser, parser.Config{}, convert.OnDuplicateLatest)
+	for i, input := range inputs {
+		assert.Empty(t, converter.ScanSuites(context.Background(), strings.NewReader(input), names[i]), "no scan error")
+	}
+	for i, input := range inputs {
+		assert.Empty(t, converter.Convert(context.Background(), strings.NewReader(input), names[i]), "no conversion error")
+	}
+	assert.Len(t, fm.Results, 1, "earlier duplicate not saved")
+	assert.Equal(t, "E2E [payments]", converter.GetReports()[0].SuiteDescription, "suite distinguished")
+	assert.Equal(t, "E2E [orders]", converter.GetReports()[1].SuiteDescription, "suite distinguished")
+
+	converter = convert.NewStreamConverter(&recordFileManager{}, namedParser, parser.Config{},
+		convert.OnDuplicateError)
+	assert.Empty(t, converter.Convert(context.Background(), strings.NewReader(inputs[0]), names[0]), "no conversion error")
+	assert.Error(t, converter.Convert(context.Background(), strings.NewReader(inputs[1]), names[1]), "conflict reported")
+}
+
+func TestStreamConverterDuplicateAttachments(t *testing.T) {
+	spec := `{"LeafNodeType": "It", "LeafNodeText": "spec", "LeafNodeLabels": ["id=6f1c1a8e-3a7e-4d1b-9f2a-1b2c3d4e5f60"],
+		"State": "passed", "EndTime": "%s", "CapturedGinkgoWriterOutput": "%s"}`
+	inputs := []string{
+		fmt.Sprintf(`[{"SuiteDescription": "E2E", "SpecReports": [{"LeafNodeType": "BeforeSuite", "State": "passed"},
+			%s, %s]}]`, fmt.Sprintf(spec, "2024-01-01T00:00:01Z", "first"), fmt.Sprintf(spec, "2024-01-01T00:00:02Z", "second")),
+		fmt.Sprintf(`[{"SuiteDescription": "E2E", "SpecReports": [%s]}]`, fmt.Sprintf(spec, "2024-01-01T00:00:03Z", "third")),
+	}
+
+	fm := fmngr.NewMemoryFileManager()
+	converter := convert.NewStreamConverter(fm, parser.NewDefaultParser, parser.Config{}, convert.OnDuplicateLatest)
+	for i, input := range inputs {
+		assert.Empty(t, converter.Convert(context.Background(), strings.NewReader(input), fmt.Sprintf("%d.json", i)),
+			"no conversion error")
+	}
+	assert.Empty(t, converter.GetSaveErrors(), "no saving errors")
+	assert.Len(t, fm.GetResults(), 1, "latest result kept")
+	attachments := []string{}
+	for _, file := range fm.GetFiles() {
+		if strings.Contains(file.Name, "-attachment") {
+			attachments = append(attachments, string(file.Content))
+		}
+	}
+	assert.Equal(t, []string{"third"}, attachments, "attachments of replaced results removed")
+	assert.Len(t, fm.GetContainers()[0].Children, 1, "duplicate is the same child of the suite")
+}
+
+func TestStreamConverterScanSuitesContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	converter := convert.NewStreamConverter(&recordFileManager{}, namedParser, parser.Config{}, convert.OnDuplicateLatest)
+	err := converter.ScanSuites(ctx, strings.NewReader(generateInput(1)), "e2e.json")
+	assert.ErrorIs(t, err, context.Canceled, "scan stopped")
+}
+
+func TestStreamConverterSameInputNames(t *testing.T) {
+	input := `[{"SuiteDescription": "E2E", "SpecReports": []}]`
+	var tests = []struct {
+		name         string
+		inputs       []string
+		descriptions []string
+	}{{
+		name:         "same file name in different folders",
+		inputs:       []string{"svc-a/report.json", "svc-b/report.json"},
+		descriptions: []string{"E2E [svc-a/report]", "E2E [svc-b/report]"},
+	}, {
+		name:         "common parent folder",
+		inputs:       []string{"/ci/reports/svc-a/report.json", "/ci/reports/svc-b/nightly/report.json"},
+		descriptions: []string{"E2E [svc-a/report]", "E2E [svc-b/nightly/report]"},
+	}, {
+		name:         "same folder",
+		inputs:       []string{"./reports/payments.json", "./reports/orders.json"},
+		descriptions: []string{"E2E [payments]", "E2E [orders]"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter := convert.NewStreamConverter(&recordFileManager{}, namedParser, parser.Config{},
+				convert.OnDuplicateLatest)
+			for _, name := range tt.inputs {
+				assert.Empty(t, converter.ScanSuites(context.Background(), strings.NewReader(input), name), "no scan error")
+			}
+			for _, name := range tt.inputs {
+				assert.Empty(t, converter.Convert(context.Background(), strings.NewReader(input), name),
+					"no conversion error")
+			}
+			descriptions := []string{}
+			for _, report := range converter.GetReports() {
+				descriptions = append(descriptions, report.SuiteDescription)
+			}
+			assert.Equal(t, tt.descriptions, descriptions, "suites distinguished")
+		})
+	}
+}
+
+func TestStreamConverterInvalidInput(t *testing.T) {
+	var tests = []struct {
+		name  string
+		input string
+	}{{
+		name:  "not array",
+		input: `{"SuiteDescription": "E2E"}`,
+	}, {
+		name:  "truncated",
+		input: `[{"SuiteDescription": "E2E", "SpecReports": [{"LeafNodeType": "It"`,
+	}, {
+		name:  "invalid specs",
+		input: `[{"SuiteDescription": "E2E", "SpecReports": "spec"}]`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter := convert.NewStreamConverter(&recordFileManager{}, namedParser, parser.Config{},
+				convert.OnDuplicateLatest)
+			assert.Error(t, converter.Convert(context.Background(), strings.NewReader(tt.input), "input.json"), "invalid input")
+			assert.Error(t, converter.ScanSuites(context.Background(), strings.NewReader(tt.input), "input.json"), "invalid input")
+		})
+	}
+}
+
+func generateInput(count int) string {
+	specs := []string{`{"LeafNodeType": "BeforeSuite", "State": "passed"}`}
+	for i := 0; i < count; i++ {
+		specs = append(specs, fmt.Sprintf(`{"LeafNodeType": "It", "LeafNodeText": "spec %d"}`, i))
+	}
+	return fmt.Sprintf(`[{"SuiteDescription": "E2E", "SpecReports": [%s]}]`, strings.Join(specs, ","))
+}
+
+func TestStreamConverterWorkers(t *testing.T) {
+	input := generateInput(200)
+	sequential := &recordFileManager{}
+	converter := convert.NewStreamConverter(sequential, namedParser, parser.Config{}, convert.OnDuplicateLatest)
+	assert.Empty(t, converter.Convert(context.Background(), strings.NewReader(input), "e2e.json"),
+		"no conversion error")
+
+	parallel := &recordFileManager{}
+	converter = convert.NewStreamConverter(parallel, namedParser, parser.Config{}, convert.OnDuplicateLatest,
+		convert.WithWorkers(8))
+	assert.Empty(t, converter.Convert(context.Background(), strings.NewReader(input), "e2e.json"),
+		"no conversion error")
+
+	sortResults := func(results []allure.Result) {
+		sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	}
+	sortResults(sequential.Results)
+	sortResults(parallel.Results)
+	assert.Equal(t, sequential.Results, parallel.Results, "the same results saved")
+	assert.Len(t, parallel.Containers, 1, "suite container saved")
+	assert.Equal(t, sequential.Containers[0].Children, parallel.Containers[0].Children,
+		"children in the order of specs")
+}
+
+func TestStreamConverterWorkersErrors(t *testing.T) {
+	failedParser := func(specReport types.SpecReport, config parser.Config) (*parser.Parser, error) {
+		if specReport.LeafNodeText == "spec 100" {
+			return nil, errTest
+		}
+		return namedParser(specReport, config)
+	}
+	converter := convert.NewStreamConverter(&recordFileManager{}, failedParser, parser.Config{},
+		convert.OnDuplicateLatest, convert.WithWorkers(4))
+	err := converter.Convert(context.Background(), strings.NewReader(generateInput(200)), "e2e.json")
+	assert.ErrorIs(t, err, errTest, "conversion error stops the pool")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, workers := range []int{1, 4} {
+		fm := &recordFileManager{}
+		converter = convert.NewStreamConverter(fm, namedParser, parser.Config{}, convert.OnDuplicateLatest,
+			convert.WithWorkers(workers))
+		err = converter.Convert(ctx, strings.NewReader(generateInput(200)), "e2e.json")
+		assert.ErrorIs(t, err, context.Canceled, "cancellation stops the pool")
+		assert.Empty(t, fm.Results, "nothing saved after cancellation")
+	}
+}
@@ -0,0 +1,183 @@
+package convert
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/report"
+	"github.com/google/uuid"
+	"github.com/ozontech/allure-go/pkg/allure"
+)
+
+const summaryNoValue = "<none>"
+
+// SummaryStatuses are Allure statuses in the order of the summary.
+var SummaryStatuses = []allure.Status{allure.Passed, allure.Failed, allure.Broken, allure.Skipped, allure.Unknown}
+
+type (
+	// Summary counts converted results by status, suite and epic. Results with the same UUID
+	// are counted once with the latest status.
+	Summary struct {
+		results  map[uuid.UUID]summaryResult
+		notIt    int
+		filtered int
+		rejected []error
+		mu       sync.Mutex
+	}
+	// StatusCounts is the number of results of each Allure status.
+	StatusCounts  map[allure.Status]int
+	summaryResult struct {
+		status allure.Status
+		suite  string
+		epic   string
+	}
+)
+
+func NewSummary() *Summary {
+	return &Summary{results: map[uuid.UUID]summaryResult{}}
+}
+
+func (s *Summary) AddResult(result allure.Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[result.UUID] = summaryResult{
+		status: result.Status,
+		suite:  report.GetLabelValue(result.Labels, report.SuiteLabelName),
+		epic:   report.GetLabelValue(result.Labels, report.EpicLabelName),
+	}
+}
+
+// AddNotIt counts spec which isn't converted to a result because it isn't It node.
+func (s *Summary) AddNotIt() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notIt++
+}
+
+// AddFiltered counts It spec which isn't converted because it doesn't match the spec filter.
+func (s *Summary) AddFiltered() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.filtered++
+}
+
+// AddRejected counts spec which isn't converted because it doesn't have mandatory labels.
+func (s *Summary) AddRejected(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rejected = append(s.rejected, err)
+}
+
+func (s *Summary) GetStatuses() StatusCounts {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := StatusCounts{}
+	for _, result := range s.results {
+		counts[result.status]++
+	}
+	return counts
+}
+
+func (s *Summary) GetSuites() map[string]StatusCounts {
+	return s.getGroups(func(result summaryResult) string { return result.suite })
+}
+
+func (s *Summary) GetEpics() map[string]StatusCounts {
+	return s.getGroups(func(result summaryResult) string { return result.epic })
+}
+
+func (s *Summary) GetNotIt() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.notIt
+}
+
+func (s *Summary) GetFiltered() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.filtered
+}
+
+// GetRejected returns errors of specs rejected for missing mandatory labels.
+func (s *Summary) GetRejected() []error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]error{}, s.rejected...)
+}
+
+// HasTestFailures checks whether there are failed or broken results.
+func (s *Summary) HasTestFailures() bool {
+	statuses := s.GetStatuses()
+	return statuses[allure.Failed] != 0 || statuses[allure.Broken] != 0
+}
+
+// WriteTo writes human-readable summary.
+func (s *Summary) WriteTo(w io.Writer) (int64, error) {
+	b := &strings.Builder{}
+	statuses := s.GetStatuses()
+	fmt.Fprintf(b, "Converted results: %d\n", statuses.Total())
+	if statuses.Total() != 0 {
+		fmt.Fprintf(b, "  %s\n", statuses)
+	}
+	writeSummaryGroups(b, "Suites", s.GetSuites())
+	writeSummaryGroups(b, "Epics", s.GetEpics())
+	fmt.Fprintf(b, "Specs which are not It nodes: %d\n", s.GetNotIt())
+	fmt.Fprintf(b, "Specs filtered out: %d\n", s.GetFiltered())
+	fmt.Fprintf(b, "Specs rejected for missing labels: %d\n", len(s.GetRejected()))
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func (s *Summary) getGroups(getKey func(summaryResult) string) map[string]StatusCounts {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	groups := map[string]StatusCounts{}
+	for _, result := range s.results {
+		key := getKey(result)
+		if key == "" {
+			key = summaryNoValue
+		}
+		if groups[key] == nil {
+			groups[key] = StatusCounts{}
+		}
+		groups[key][result.status]++
+	}
+	return groups
+}
+
+func (c StatusCounts) Total() int {
+	total := 0
+	for _, count := range c {
+		total += count
+	}
+	return total
+}
+
+// String lists non-zero counts in the order of SummaryStatuses.
+func (c StatusCounts) String() string {
+	parts := []string{}
+	for _, status := range SummaryStatuses {
+		if c[status] != 0 {
+			parts = append(parts, fmt.Sprintf("%s: %d", status, c[status]))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func writeSummaryGroups(b *strings.Builder, title string, groups map[string]StatusCounts) {
+	if len(groups) == 0 {
+		return
+	}
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(b, "%s:\n", title)
+	for _, name := range names {
+		fmt.Fprintf(b, "  %s: %d (%s)\n", name, groups[name].Total(), groups[name])
+	}
+}
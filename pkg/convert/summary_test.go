@@ -0,0 +1,98 @@
+package convert_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Moon1706/ginkgo2allure/pkg/convert"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/parser"
+	"github.com/Moon1706/ginkgo2allure/pkg/convert/report"
+	"github.com/google/uuid"
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummary(t *testing.T) {
+	summary := convert.NewSummary()
+	id := uuid.New()
+	summary.AddResult(allure.Result{UUID: id, Status: allure.Failed,
+		Labels: []*allure.Label{allure.SuiteLabel("E2E"), allure.EpicLabel("payments")}})
+	summary.AddResult(allure.Result{UUID: id, Status: allure.Passed,
+		Labels: []*allure.Label{allure.SuiteLabel("E2E"), allure.EpicLabel("payments")}})
+	summary.AddResult(allure.Result{UUID: uuid.New(), Status: allure.Broken,
+		Labels: []*allure.Label{allure.SuiteLabel("E2E")}})
+	summary.AddResult(allure.Result{UUID: uuid.New(), Status: allure.Skipped,
+		Labels: []*allure.Label{allure.SuiteLabel("Backup")}})
+	summary.AddNotIt()
+	summary.AddFiltered()
+	summary.AddRejected(errors.New("no id"))
+
+	assert.Equal(t, convert.StatusCounts{allure.Passed: 1, allure.Broken: 1, allure.Skipped: 1},
+		summary.GetStatuses(), "duplicate counted once")
+	assert.True(t, summary.HasTestFailures(), "broken result")
+	assert.Equal(t, map[string]convert.StatusCounts{
+		"payments": {allure.Passed: 1},
+		"<none>":   {allure.Broken: 1, allure.Skipped: 1},
+	}, summary.GetEpics(), "results by epic")
+
+	b := &strings.Builder{}
+	_, err := summary.WriteTo(b)
+	assert.Empty(t, err, "summary written")
+	assert.Equal(t, `Converted results: 3
+  passed: 1, broken: 1, skipped: 1
+Suites:
+  Backup: 1 (skipped: 1)
+  E2E: 2 (passed: 1, broken: 1)
+Epics:
+  <none>: 2 (broken: 1, skipped: 1)
+  payments: 1 (passed: 1)
+Specs which are not It nodes: 1
+Specs filtered out: 1
+Specs rejected for missing labels: 1
+`, b.String(), "human-readable summary")
+}
+
+func TestStreamConverterRejectSpecs(t *testing.T) {
+	id := uuid.New().String()
+	input := `[{"SuiteDescription": "E2E", "SpecReports": [
+		{"LeafNodeType": "BeforeSuite", "State": "passed"},
+		{"LeafNodeType": "It", "LeafNodeText": "labeled", "State": "failed", "LeafNodeLabels": ["id=` + id + `"]},
+		{"LeafNodeType": "It", "LeafNodeText": "unlabeled", "State": "passed"}
+	]}]`
+
+	fm := &recordFileManager{}
+	converter := convert.NewStreamConverter(fm, parser.NewDefaultParser, parser.Config{}, convert.OnDuplicateLatest,
+		convert.WillRejectSpecsWithoutLabels(true))
+	err := converter.Convert(context.Background(), strings.NewReader(input), "e2e.json")
+	assert.Empty(t, err, "spec without labels doesn't stop conversion")
+	assert.Len(t, fm.Results, 1, "labeled spec converted")
+	summary := converter.GetSummary()
+	assert.Len(t, summary.GetRejected(), 1, "spec without labels rejected")
+	assert.ErrorIs(t, summary.GetRejected()[0], report.ErrMissingLabel, "missing label error")
+	assert.Equal(t, 1, summary.GetNotIt(), "BeforeSuite isn't It")
+	assert.Equal(t, convert.StatusCounts{allure.Failed: 1}, summary.GetStatuses(), "converted results counted")
+
+	converter = convert.NewStreamConverter(&recordFileManager{}, parser.NewDefaultParser, parser.Config{},
+		convert.OnDuplicateLatest)
+	err = converter.Convert(context.Background(), strings.NewReader(input), "e2e.json")
+	assert.ErrorIs(t, err, report.ErrMissingLabel, "conversion stopped by default")
+}
+
+func TestStreamConverterFilterSpecs(t *testing.T) {
+	input := `[{"SuiteDescription": "E2E", "SuiteLabels": ["e2e"], "SpecReports": [
+		{"LeafNodeType": "It", "LeafNodeText": "smoke", "State": "passed", "LeafNodeLabels": ["smoke"]},
+		{"LeafNodeType": "It", "LeafNodeText": "slow", "State": "passed", "LeafNodeLabels": ["slow"]}
+	]}]`
+	specFilter, err := parser.NewSpecFilter("e2e && smoke", nil)
+	assert.Empty(t, err, "spec filter created")
+
+	fm := &recordFileManager{}
+	converter := convert.NewStreamConverter(fm, namedParser, parser.Config{SpecFilter: specFilter},
+		convert.OnDuplicateLatest)
+	err = converter.Convert(context.Background(), strings.NewReader(input), "e2e.json")
+	assert.Empty(t, err, "no conversion error")
+	assert.Len(t, fm.Results, 1, "matching spec converted")
+	assert.Equal(t, 1, converter.GetSummary().GetFiltered(), "filtered spec counted")
+}
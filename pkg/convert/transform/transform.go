@@ -14,6 +14,14 @@ const (
 	DefaultGetErrorDuringAlalyze = true
 )
 
+var (
+	BeforeEachNodeTypes = types.NodeTypeBeforeEach | types.NodeTypeJustBeforeEach | types.NodeTypeBeforeAll |
+		types.NodeTypeReportBeforeEach
+	AfterEachNodeTypes = types.NodeTypeAfterEach | types.NodeTypeJustAfterEach | types.NodeTypeAfterAll |
+		types.NodeTypeReportAfterEach | types.NodeTypeCleanupInvalid | types.NodeTypeCleanupAfterEach |
+		types.NodeTypeCleanupAfterAll
+)
+
 type Node struct {
 	BeginEvent types.SpecEvent
 	EndEvent   types.SpecEvent
@@ -29,12 +37,19 @@ type (
 		analyzeErrors         bool
 		getErrorDuringAlalyze bool
 		filterEvents          FilterEvents
+		stepHooks             []StepHook
 		nodes                 []Node
 		errNode               Node
 		steps                 []*allure.Step
+		befores               []*allure.Step
+		afters                []*allure.Step
+		attempts              [][]*allure.Step
 	}
 	Opt          func(o *DefaultTransform)
 	FilterEvents func(event types.SpecEvent) bool
+	// StepHook is called for each created step after steps nesting with
+	// the timeline locations of step begin and end events.
+	StepHook func(step *allure.Step, begin, end types.TimelineLocation)
 )
 
 func WillAnalyzeErrors(analyzeErrors, getErrorDuringAlalyze bool) Opt {
@@ -50,6 +65,12 @@ func WithFilterEvents(filter FilterEvents) Opt {
 	}
 }
 
+func WithStepHooks(hooks ...StepHook) Opt {
+	return func(o *DefaultTransform) {
+		o.stepHooks = append(o.stepHooks, hooks...)
+	}
+}
+
 func NewTransform(opts ...Opt) *DefaultTransform {
 	filterSuiteAndEachEvents := func(event types.SpecEvent) bool {
 		return event.NodeType != types.NodeTypeInvalid &&
@@ -67,7 +88,13 @@ func NewTransform(opts ...Opt) *DefaultTransform {
 }
 
 func (t *DefaultTransform) AnalyzeEvents(events types.SpecEvents, failure types.Failure) error {
-	t.nodes = t.findNodes(events)
+	attemptsEvents := t.splitAttempts(events)
+	events = attemptsEvents[len(attemptsEvents)-1]
+	t.attempts = [][]*allure.Step{}
+	for _, attemptEvents := range attemptsEvents[:len(attemptsEvents)-1] {
+		t.attempts = append(t.attempts, t.getNestedSteps(t.findNodes(attemptEvents, t.filterEvents), Node{}))
+	}
+	t.nodes = t.findNodes(events, t.filterEvents)
 	if failure.Message != "" && t.analyzeErrors {
 		errNode, err := t.findErrorNode(t.nodes, failure)
 		if err != nil && t.getErrorDuringAlalyze {
@@ -76,6 +103,8 @@ func (t *DefaultTransform) AnalyzeEvents(events types.SpecEvents, failure types.
 		t.errNode = errNode
 	}
 	t.steps = t.getNestedSteps(t.nodes, t.errNode)
+	t.befores = t.getFixtureSteps(events, BeforeEachNodeTypes, failure)
+	t.afters = t.getFixtureSteps(events, AfterEachNodeTypes, failure)
 	return nil
 }
 
@@ -83,9 +112,57 @@ func (t *DefaultTransform) GetAllureSteps() []*allure.Step {
 	return t.steps
 }
 
-func (t *DefaultTransform) findNodes(events types.SpecEvents) (nodes []Node) {
+// GetAllureFixtures returns steps of setup and teardown nodes (BeforeEach, AfterEach, etc.)
+// which were run for the spec.
+func (t *DefaultTransform) GetAllureFixtures() (befores, afters []*allure.Step) {
+	return t.befores, t.afters
+}
+
+// GetAllureAttempts returns steps of each previous attempt of the spec, if the spec
+// was retried (FlakeAttempts) or repeated (MustPassRepeatedly). Steps of the last
+// attempt are returned by GetAllureSteps.
+func (t *DefaultTransform) GetAllureAttempts() [][]*allure.Step {
+	return t.attempts
+}
+
+func (t *DefaultTransform) splitAttempts(events types.SpecEvents) []types.SpecEvents {
+	attempts := []types.SpecEvents{{}}
+	for _, event := range events {
+		if event.SpecEventType.Is(types.SpecEventSpecRetry | types.SpecEventSpecRepeat) {
+			attempts = append(attempts, types.SpecEvents{})
+			continue
+		}
+		attempts[len(attempts)-1] = append(attempts[len(attempts)-1], event)
+	}
+	return attempts
+}
+
+func (t *DefaultTransform) getFixtureSteps(events types.SpecEvents, nodeTypes types.NodeType,
+	failure types.Failure) []*allure.Step {
+	nodes := t.findNodes(events, func(event types.SpecEvent) bool {
+		return !event.NodeType.Is(nodeTypes)
+	})
+	steps := make([]*allure.Step, 0, len(nodes))
+	for _, node := range nodes {
+		stepStatus := allure.Passed
+		if failure.FailureNodeType == node.BeginEvent.NodeType &&
+			failure.FailureNodeLocation.FileName == node.BeginEvent.CodeLocation.FileName &&
+			failure.FailureNodeLocation.LineNumber == node.BeginEvent.CodeLocation.LineNumber {
+			stepStatus = allure.Failed
+		}
+		steps = append(steps, &allure.Step{
+			Name:   getStepName(node.BeginEvent),
+			Status: stepStatus,
+			Start:  node.BeginEvent.TimelineLocation.Time.UnixMilli(),
+			Stop:   node.EndEvent.TimelineLocation.Time.UnixMilli(),
+		})
+	}
+	return steps
+}
+
+func (t *DefaultTransform) findNodes(events types.SpecEvents, filterEvents FilterEvents) (nodes []Node) {
 	for _, event := range events {
-		if t.filterEvents(event) {
+		if filterEvents(event) {
 			continue
 		}
 		if event.SpecEventType != types.SpecEventNodeStart &&
@@ -118,10 +195,7 @@ func (t *DefaultTransform) getNestedSteps(nodes []Node, errNode Node) []*allure.
 	finalSteps := []*allure.Step{}
 	steps := make([]*allure.Step, 0, len(nodes))
 	for i := range nodes {
-		stepName := nodes[i].BeginEvent.Message
-		if nodes[i].BeginEvent.NodeType != types.NodeTypeInvalid {
-			stepName = fmt.Sprintf("[%s] %s", nodes[i].BeginEvent.NodeType.String(), stepName)
-		}
+		stepName := getStepName(nodes[i].BeginEvent)
 		stepStatus := allure.Passed
 		if nodes[i].BeginEvent.CodeLocation.FileName == errNode.BeginEvent.CodeLocation.FileName &&
 			nodes[i].BeginEvent.CodeLocation.LineNumber == errNode.BeginEvent.CodeLocation.LineNumber {
@@ -145,9 +219,21 @@ func (t *DefaultTransform) getNestedSteps(nodes []Node, errNode Node) []*allure.
 			}
 		}
 	}
+	for i := range steps {
+		for _, hook := range t.stepHooks {
+			hook(steps[i], nodes[i].BeginEvent.TimelineLocation, nodes[i].EndEvent.TimelineLocation)
+		}
+	}
 	return finalSteps
 }
 
+func getStepName(event types.SpecEvent) string {
+	if event.NodeType != types.NodeTypeInvalid {
+		return fmt.Sprintf("[%s] %s", event.NodeType.String(), event.Message)
+	}
+	return event.Message
+}
+
 func (t *DefaultTransform) findErrorNode(nodes []Node, failure types.Failure) (Node, error) {
 	traceFiles, err := t.getTraceFiles(failure)
 	if err != nil {
@@ -200,6 +286,9 @@ func (t *DefaultTransform) stepInTrace(node Node, traceFiles []TraceFile) bool {
 }
 
 func (t *DefaultTransform) findErrorRootNode(nodes []Node, traceFiles []TraceFile) (errNode Node) {
+	if len(traceFiles) == 0 {
+		return
+	}
 	lastTraceFile := traceFiles[len(traceFiles)-1]
 	for _, node := range nodes {
 		if node.BeginEvent.CodeLocation.FileName == lastTraceFile.FileName &&
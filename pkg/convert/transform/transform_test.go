@@ -141,3 +141,57 @@ func readReports[T any](filePath string) (T, error) {
 	err = json.Unmarshal(file, out)
 	return *out, err
 }
+
+func TestTransformStepHooks(t *testing.T) {
+	ginkoReportPath := filepath.Join(ginkgoReportFolderPath, "basic_success.json")
+	ginkgoReports, err := readReports[[]types.Report](ginkoReportPath)
+	assert.Empty(t, err, "no error during ginkgo report unmarshaling")
+
+	hookedSteps := []*allure.Step{}
+	tr := transform.NewTransform(transform.WithStepHooks(
+		func(step *allure.Step, begin, end types.TimelineLocation) {
+			assert.LessOrEqual(t, begin.Order, end.Order, "begin event is before end event")
+			hookedSteps = append(hookedSteps, step)
+		}))
+	specReport := ginkgoReports[0].SpecReports[0]
+	err = tr.AnalyzeEvents(specReport.SpecEvents, specReport.Failure)
+	assert.Empty(t, err, "no error during analyze")
+	assert.Len(t, hookedSteps, countSteps(tr.GetAllureSteps()), "hook called for each step")
+}
+
+func countSteps(steps []*allure.Step) int {
+	count := len(steps)
+	for _, step := range steps {
+		count += countSteps(step.Steps)
+	}
+	return count
+}
+
+func TestTransformAttempts(t *testing.T) {
+	itEvent := func(eventType types.SpecEventType, order int) types.SpecEvent {
+		return types.SpecEvent{
+			SpecEventType:    eventType,
+			NodeType:         types.NodeTypeIt,
+			Message:          "test",
+			CodeLocation:     types.CodeLocation{FileName: "test.go", LineNumber: 1},
+			TimelineLocation: types.TimelineLocation{Order: order},
+		}
+	}
+	events := types.SpecEvents{
+		itEvent(types.SpecEventNodeStart, 1),
+		itEvent(types.SpecEventNodeEnd, 2),
+		{SpecEventType: types.SpecEventSpecRetry, Attempt: 1, TimelineLocation: types.TimelineLocation{Order: 3}},
+		itEvent(types.SpecEventNodeStart, 4),
+		itEvent(types.SpecEventNodeEnd, 5),
+	}
+	tr := transform.NewTransform(transform.WillAnalyzeErrors(false, false))
+	err := tr.AnalyzeEvents(events, types.Failure{})
+	assert.Empty(t, err, "no error during analyze")
+	assert.Len(t, tr.GetAllureAttempts(), 1, "one previous attempt")
+	assert.Len(t, tr.GetAllureAttempts()[0], 1, "previous attempt steps")
+	assert.Len(t, tr.GetAllureSteps(), 1, "last attempt steps")
+
+	err = tr.AnalyzeEvents(events[:2], types.Failure{})
+	assert.Empty(t, err, "no error during analyze")
+	assert.Empty(t, tr.GetAllureAttempts(), "spec wasn't retried")
+}
@@ -0,0 +1,182 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	fmngr "github.com/Moon1706/ginkgo2allure/pkg/convert/file_manager"
+	"github.com/pkg/errors"
+)
+
+const (
+	SendResultsPath = "/allure-docker-service/send-results"
+
+	DefaultProjectID  = "default"
+	DefaultRetries    = 3
+	DefaultRetryDelay = time.Second
+	// DefaultChunkSize limits the size of files content sent in one request.
+	DefaultChunkSize = 10 << 20
+)
+
+type (
+	// Client sends results to allure-docker-service with send-results API.
+	Client struct {
+		url        string
+		projectID  string
+		token      string
+		retries    int
+		retryDelay time.Duration
+		chunkSize  int
+		httpClient *http.Client
+	}
+	Opt func(c *Client)
+
+	sendResultsRequest struct {
+		Results []sendResultsFile `json:"results"`
+	}
+	sendResultsFile struct {
+		FileName      string `json:"file_name"`
+		ContentBase64 string `json:"content_base64"`
+	}
+)
+
+func WithProjectID(projectID string) Opt {
+	return func(c *Client) {
+		c.projectID = projectID
+	}
+}
+
+// WithToken sets access token, which is sent in the Authorization header.
+func WithToken(token string) Opt {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithRetries sets the number of retries of failed requests. The delay doubles after each retry.
+func WithRetries(retries int, delay time.Duration) Opt {
+	return func(c *Client) {
+		c.retries = retries
+		c.retryDelay = delay
+	}
+}
+
+func WithChunkSize(chunkSize int) Opt {
+	return func(c *Client) {
+		c.chunkSize = chunkSize
+	}
+}
+
+func WithHTTPClient(httpClient *http.Client) Opt {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+func NewClient(serverURL string, opts ...Opt) *Client {
+	c := &Client{
+		url:        strings.TrimSuffix(serverURL, "/"),
+		projectID:  DefaultProjectID,
+		retries:    DefaultRetries,
+		retryDelay: DefaultRetryDelay,
+		chunkSize:  DefaultChunkSize,
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// SendResults sends files in chunks, each chunk contains at least one file.
+func (c *Client) SendResults(ctx context.Context, files []fmngr.MemoryFile) error {
+	for _, chunk := range c.getChunks(files) {
+		err := c.sendChunk(ctx, chunk)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) getChunks(files []fmngr.MemoryFile) [][]fmngr.MemoryFile {
+	chunks := [][]fmngr.MemoryFile{}
+	chunk := []fmngr.MemoryFile{}
+	size := 0
+	for _, file := range files {
+		if len(chunk) != 0 && size+len(file.Content) > c.chunkSize {
+			chunks = append(chunks, chunk)
+			chunk, size = []fmngr.MemoryFile{}, 0
+		}
+		chunk = append(chunk, file)
+		size += len(file.Content)
+	}
+	if len(chunk) != 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+func (c *Client) sendChunk(ctx context.Context, chunk []fmngr.MemoryFile) error {
+	request := sendResultsRequest{Results: make([]sendResultsFile, 0, len(chunk))}
+	for _, file := range chunk {
+		request.Results = append(request.Results, sendResultsFile{
+			FileName:      file.Name,
+			ContentBase64: base64.StdEncoding.EncodeToString(file.Content),
+		})
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return errors.Wrap(err, "Failed marshal results")
+	}
+
+	delay := c.retryDelay
+	for attempt := 0; ; attempt++ {
+		retry, err := c.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= c.retries {
+			return errors.Wrap(err, "Cannot send results")
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		delay *= 2
+	}
+}
+
+// post sends the request and returns whether it can be retried after an error.
+func (c *Client) post(ctx context.Context, body []byte) (bool, error) {
+	sendURL := fmt.Sprintf("%s%s?project_id=%s", c.url, SendResultsPath, url.QueryEscape(c.projectID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sendURL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return false, nil
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	return resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests, err
+}
@@ -0,0 +1,130 @@
+package upload_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	fmngr "github.com/Moon1706/ginkgo2allure/pkg/convert/file_manager"
+	"github.com/Moon1706/ginkgo2allure/pkg/upload"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	sendResultsRequest struct {
+		Results []struct {
+			FileName      string `json:"file_name"`
+			ContentBase64 string `json:"content_base64"`
+		} `json:"results"`
+	}
+	mockServer struct {
+		mu       sync.Mutex
+		failures int
+		status   int
+		requests []*http.Request
+		files    [][]string
+	}
+)
+
+func (s *mockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r)
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(s.status)
+		return
+	}
+	request := sendResultsRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	files := []string{}
+	for _, file := range request.Results {
+		content, _ := base64.StdEncoding.DecodeString(file.ContentBase64)
+		files = append(files, file.FileName+":"+string(content))
+	}
+	s.files = append(s.files, files)
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestClientSendResults(t *testing.T) {
+	server := &mockServer{}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	files := []fmngr.MemoryFile{
+		{Name: "a-result.json", Content: []byte("aaaa")},
+		{Name: "b-result.json", Content: []byte("bbbb")},
+		{Name: "c-attachment.txt", Content: []byte("cccccccccc")},
+	}
+	client := upload.NewClient(ts.URL+"/", upload.WithProjectID("e2e"), upload.WithToken("secret"),
+		upload.WithChunkSize(8))
+	err := client.SendResults(context.Background(), files)
+	assert.Empty(t, err, "results sent")
+	assert.Equal(t, [][]string{
+		{"a-result.json:aaaa", "b-result.json:bbbb"},
+		{"c-attachment.txt:cccccccccc"},
+	}, server.files, "files sent in chunks")
+	assert.Equal(t, upload.SendResultsPath, server.requests[0].URL.Path, "send-results API")
+	assert.Equal(t, "e2e", server.requests[0].URL.Query().Get("project_id"), "project")
+	assert.Equal(t, "Bearer secret", server.requests[0].Header.Get("Authorization"), "token auth")
+}
+
+func TestClientRetries(t *testing.T) {
+	var tests = []struct {
+		name     string
+		failures int
+		status   int
+		requests int
+		err      bool
+	}{{
+		name:     "retried server error",
+		failures: 2,
+		status:   http.StatusServiceUnavailable,
+		requests: 3,
+	}, {
+		name:     "too many server errors",
+		failures: 5,
+		status:   http.StatusInternalServerError,
+		requests: 3,
+		err:      true,
+	}, {
+		name:     "client error not retried",
+		failures: 1,
+		status:   http.StatusUnauthorized,
+		requests: 1,
+		err:      true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &mockServer{failures: tt.failures, status: tt.status}
+			ts := httptest.NewServer(server)
+			defer ts.Close()
+
+			client := upload.NewClient(ts.URL, upload.WithRetries(2, time.Millisecond))
+			err := client.SendResults(context.Background(), []fmngr.MemoryFile{{Name: "a-result.json"}})
+			assert.Equal(t, tt.err, err != nil, "send error")
+			assert.Len(t, server.requests, tt.requests, "requests count")
+		})
+	}
+}
+
+func TestClientCancellation(t *testing.T) {
+	server := &mockServer{failures: 1, status: http.StatusBadGateway}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	client := upload.NewClient(ts.URL, upload.WithRetries(1, time.Minute))
+	err := client.SendResults(ctx, []fmngr.MemoryFile{{Name: "a-result.json"}})
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "retry delay stopped")
+}